@@ -0,0 +1,207 @@
+// Package contract describes messages exchanged by the gateway and the storage service
+package contract
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sort"
+)
+
+// Actions understood by the storage service. Every action needs an entry in actionSpecs,
+// a method in Handler and a case in Dispatch
+const (
+	ActionCreateStore          = "create_store"
+	ActionCreateStoreVersion   = "create_store_version"
+	ActionDeleteStore          = "delete_store"
+	ActionDeleteStoreVersion   = "delete_store_version"
+	ActionGetStore             = "get_store"
+	ActionGetStoreDiff         = "get_store_diff"
+	ActionGetStoreHistory      = "get_store_history"
+	ActionGetStoreVersion      = "get_store_version"
+	ActionListCities           = "list_cities"
+	ActionListStores           = "list_stores"
+	ActionRestoreStoreVersion  = "restore_store_version"
+	ActionSearchStores         = "search_stores"
+	ActionUndeleteStore        = "undelete_store"
+	ActionUndeleteStoreVersion = "undelete_store_version"
+)
+
+// ActionSpec describes the messages of an action. RoutingKey is the key of the storage topic exchange,
+// reads and writes are bound to separate queues by it. StoreID, VersionID and Login tell which envelope
+// fields are required. Payload is the zero value of the payload type, nil for actions without data
+type ActionSpec struct {
+	RoutingKey string
+	StoreID    bool
+	VersionID  bool
+	Login      bool
+
+	Payload         interface{}
+	PayloadOptional bool
+}
+
+var actionSpecs = map[string]ActionSpec{
+	ActionCreateStore: {
+		RoutingKey: "store.create",
+		Login:      true,
+		Payload:    CreateStoreData{},
+	},
+	ActionCreateStoreVersion: {
+		RoutingKey: "store.version.create",
+		StoreID:    true,
+		Login:      true,
+		Payload:    CreateStoreVersionData{},
+	},
+	ActionDeleteStore: {
+		RoutingKey: "store.delete",
+		StoreID:    true,
+		Login:      true,
+	},
+	ActionDeleteStoreVersion: {
+		RoutingKey: "store.version.delete",
+		StoreID:    true,
+		VersionID:  true,
+		Login:      true,
+	},
+	ActionGetStore: {
+		RoutingKey:      "store.get",
+		StoreID:         true,
+		Payload:         PointInTimeData{},
+		PayloadOptional: true,
+	},
+	ActionGetStoreDiff: {
+		RoutingKey: "store.diff.get",
+		StoreID:    true,
+		Payload:    StoreDiffData{},
+	},
+	ActionGetStoreHistory: {
+		RoutingKey:      "store.history.get",
+		StoreID:         true,
+		Payload:         StoreHistoryData{},
+		PayloadOptional: true,
+	},
+	ActionGetStoreVersion: {
+		RoutingKey: "store.version.get",
+		StoreID:    true,
+		VersionID:  true,
+	},
+	ActionListCities: {
+		RoutingKey:      "store.city.list.get",
+		Payload:         ListCitiesData{},
+		PayloadOptional: true,
+	},
+	ActionListStores: {
+		RoutingKey:      "store.list.get",
+		Payload:         ListStoresData{},
+		PayloadOptional: true,
+	},
+	ActionRestoreStoreVersion: {
+		RoutingKey: "store.version.restore",
+		StoreID:    true,
+		VersionID:  true,
+		Login:      true,
+	},
+	ActionSearchStores: {
+		RoutingKey: "store.search.get",
+		Payload:    SearchStoresData{},
+	},
+	ActionUndeleteStore: {
+		RoutingKey: "store.undelete",
+		StoreID:    true,
+		Login:      true,
+	},
+	ActionUndeleteStoreVersion: {
+		RoutingKey: "store.version.undelete",
+		StoreID:    true,
+		VersionID:  true,
+		Login:      true,
+	},
+}
+
+// Spec returns the spec of the action, ok is false for unknown actions
+func Spec(action string) (spec ActionSpec, ok bool) {
+	spec, ok = actionSpecs[action]
+	return spec, ok
+}
+
+// Actions returns every action with a spec in alphabetical order
+func Actions() []string {
+	actions := make([]string, 0, len(actionSpecs))
+	for action := range actionSpecs {
+		actions = append(actions, action)
+	}
+	sort.Strings(actions)
+	return actions
+}
+
+// RoutingKey returns the routing key requests with the action are published with
+func RoutingKey(action string) (string, bool) {
+	spec, ok := actionSpecs[action]
+	return spec.RoutingKey, ok
+}
+
+// ErrUnknownAction is returned by Dispatch for actions without a Handler method
+var ErrUnknownAction = errors.New("unknown action")
+
+// Handler has a method for every action. Adding an action adds a method here, so an implementation
+// that doesn't handle the new action fails to compile. T is the message type of the implementation
+type Handler[T any] interface {
+	CreateStore(ctx context.Context, message T) error
+	CreateStoreVersion(ctx context.Context, message T) error
+	DeleteStore(ctx context.Context, message T) error
+	DeleteStoreVersion(ctx context.Context, message T) error
+	GetStore(ctx context.Context, message T) error
+	GetStoreDiff(ctx context.Context, message T) error
+	GetStoreHistory(ctx context.Context, message T) error
+	GetStoreVersion(ctx context.Context, message T) error
+	ListCities(ctx context.Context, message T) error
+	ListStores(ctx context.Context, message T) error
+	RestoreStoreVersion(ctx context.Context, message T) error
+	SearchStores(ctx context.Context, message T) error
+	UndeleteStore(ctx context.Context, message T) error
+	UndeleteStoreVersion(ctx context.Context, message T) error
+}
+
+// Dispatch calls the method of h for the action
+func Dispatch[T any](ctx context.Context, h Handler[T], action string, message T) error {
+	switch action {
+	case ActionCreateStore:
+		return h.CreateStore(ctx, message)
+	case ActionCreateStoreVersion:
+		return h.CreateStoreVersion(ctx, message)
+	case ActionDeleteStore:
+		return h.DeleteStore(ctx, message)
+	case ActionDeleteStoreVersion:
+		return h.DeleteStoreVersion(ctx, message)
+	case ActionGetStore:
+		return h.GetStore(ctx, message)
+	case ActionGetStoreDiff:
+		return h.GetStoreDiff(ctx, message)
+	case ActionGetStoreHistory:
+		return h.GetStoreHistory(ctx, message)
+	case ActionGetStoreVersion:
+		return h.GetStoreVersion(ctx, message)
+	case ActionListCities:
+		return h.ListCities(ctx, message)
+	case ActionListStores:
+		return h.ListStores(ctx, message)
+	case ActionRestoreStoreVersion:
+		return h.RestoreStoreVersion(ctx, message)
+	case ActionSearchStores:
+		return h.SearchStores(ctx, message)
+	case ActionUndeleteStore:
+		return h.UndeleteStore(ctx, message)
+	case ActionUndeleteStoreVersion:
+		return h.UndeleteStoreVersion(ctx, message)
+	default:
+		return ErrUnknownAction
+	}
+}
+
+// NewPayload returns a pointer to a new zero payload to decode the data into, nil for actions without data
+func (s ActionSpec) NewPayload() interface{} {
+	if s.Payload == nil {
+		return nil
+	}
+	return reflect.New(reflect.TypeOf(s.Payload)).Interface()
+}
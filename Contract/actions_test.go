@@ -0,0 +1,77 @@
+package contract
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+// recorder is a Handler that remembers the actions it was called for
+type recorder map[string]int
+
+func (r recorder) record(action string) error { r[action]++; return nil }
+
+func (r recorder) CreateStore(context.Context, string) error { return r.record(ActionCreateStore) }
+func (r recorder) CreateStoreVersion(context.Context, string) error {
+	return r.record(ActionCreateStoreVersion)
+}
+func (r recorder) DeleteStore(context.Context, string) error { return r.record(ActionDeleteStore) }
+func (r recorder) DeleteStoreVersion(context.Context, string) error {
+	return r.record(ActionDeleteStoreVersion)
+}
+func (r recorder) GetStore(context.Context, string) error     { return r.record(ActionGetStore) }
+func (r recorder) GetStoreDiff(context.Context, string) error { return r.record(ActionGetStoreDiff) }
+func (r recorder) GetStoreHistory(context.Context, string) error {
+	return r.record(ActionGetStoreHistory)
+}
+func (r recorder) GetStoreVersion(context.Context, string) error {
+	return r.record(ActionGetStoreVersion)
+}
+func (r recorder) ListCities(context.Context, string) error { return r.record(ActionListCities) }
+func (r recorder) ListStores(context.Context, string) error { return r.record(ActionListStores) }
+func (r recorder) RestoreStoreVersion(context.Context, string) error {
+	return r.record(ActionRestoreStoreVersion)
+}
+func (r recorder) SearchStores(context.Context, string) error  { return r.record(ActionSearchStores) }
+func (r recorder) UndeleteStore(context.Context, string) error { return r.record(ActionUndeleteStore) }
+func (r recorder) UndeleteStoreVersion(context.Context, string) error {
+	return r.record(ActionUndeleteStoreVersion)
+}
+
+// TestDispatch fails when an action with a spec is missing in Dispatch or goes to the method of another action
+func TestDispatch(t *testing.T) {
+	calls := recorder{}
+
+	for _, action := range Actions() {
+		if err := Dispatch[string](context.Background(), calls, action, ""); err != nil {
+			t.Errorf("%s: %v", action, err)
+		}
+		if calls[action] != 1 {
+			t.Errorf("%s was dispatched to %v", action, calls)
+		}
+
+		spec, _ := Spec(action)
+		if spec.RoutingKey == "" {
+			t.Errorf("%s has no routing key", action)
+		}
+	}
+
+	if err := Dispatch[string](context.Background(), calls, "drop_tables", ""); !errors.Is(err, ErrUnknownAction) {
+		t.Errorf("unknown action: got %v", err)
+	}
+}
+
+// TestEveryPayloadHasProtobufMessage fails when a payload type can't be sent as protobuf
+func TestEveryPayloadHasProtobufMessage(t *testing.T) {
+	for _, action := range Actions() {
+		spec, _ := Spec(action)
+		if spec.Payload == nil {
+			continue
+		}
+
+		_, err := EncodeRequest(Request{Action: action, Payload: spec.NewPayload()}, ContentTypeProtobuf)
+		if err != nil {
+			t.Errorf("%s: %v", action, err)
+		}
+	}
+}
@@ -0,0 +1,17 @@
+package contract
+
+import "errors"
+
+// Content types requests and replies can be encoded in. The storage service replies
+// in the content type of the request, so JSON and protobuf producers can work side by side
+const (
+	ContentTypeJSON     = "application/json"
+	ContentTypeProtobuf = "application/x-protobuf"
+)
+
+var ErrUnsupportedContentType = errors.New("unsupported content type")
+
+// IsSupportedContentType reports whether requests and replies can be encoded in the content type
+func IsSupportedContentType(contentType string) bool {
+	return contentType == ContentTypeJSON || contentType == ContentTypeProtobuf
+}
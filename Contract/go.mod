@@ -0,0 +1,11 @@
+module Contract
+
+go 1.21.0
+
+require (
+	github.com/streadway/amqp v1.1.0
+	go.uber.org/zap v1.26.0
+	google.golang.org/protobuf v1.31.0
+)
+
+require go.uber.org/multierr v1.11.0 // indirect
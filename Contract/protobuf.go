@@ -0,0 +1,550 @@
+package contract
+
+import (
+	"Contract/pb"
+	"encoding/json"
+	"fmt"
+)
+
+// requestToProto converts the request, its payload must be one of the request data structs
+func requestToProto(request Request) (*pb.Request, error) {
+	message := &pb.Request{
+		SchemaVersion:  int32(request.SchemaVersion),
+		Action:         request.Action,
+		StoreId:        request.StoreID,
+		UserLogin:      request.UserLogin,
+		VersionId:      request.VersionID,
+		IdempotencyKey: request.IdempotencyKey,
+	}
+
+	switch data := request.Payload.(type) {
+	case nil:
+	case CreateStoreData:
+		message.Data = &pb.Request_CreateStore{CreateStore: createStoreDataToProto(data)}
+	case *CreateStoreData:
+		message.Data = &pb.Request_CreateStore{CreateStore: createStoreDataToProto(*data)}
+	case CreateStoreVersionData:
+		message.Data = &pb.Request_CreateStoreVersion{CreateStoreVersion: createStoreVersionDataToProto(data)}
+	case *CreateStoreVersionData:
+		message.Data = &pb.Request_CreateStoreVersion{CreateStoreVersion: createStoreVersionDataToProto(*data)}
+	case StoreDiffData:
+		message.Data = &pb.Request_StoreDiff{StoreDiff: storeDiffDataToProto(data)}
+	case *StoreDiffData:
+		message.Data = &pb.Request_StoreDiff{StoreDiff: storeDiffDataToProto(*data)}
+	case PointInTimeData:
+		message.Data = &pb.Request_PointInTime{PointInTime: &pb.PointInTimeData{At: data.At}}
+	case *PointInTimeData:
+		message.Data = &pb.Request_PointInTime{PointInTime: &pb.PointInTimeData{At: data.At}}
+	case ListStoresData:
+		message.Data = &pb.Request_ListStores{ListStores: listStoresDataToProto(data)}
+	case *ListStoresData:
+		message.Data = &pb.Request_ListStores{ListStores: listStoresDataToProto(*data)}
+	case StoreHistoryData:
+		message.Data = &pb.Request_StoreHistory{StoreHistory: storeHistoryDataToProto(data)}
+	case *StoreHistoryData:
+		message.Data = &pb.Request_StoreHistory{StoreHistory: storeHistoryDataToProto(*data)}
+	case ListCitiesData:
+		message.Data = &pb.Request_ListCities{ListCities: &pb.ListCitiesData{Limit: int32(data.Limit), Cursor: data.Cursor}}
+	case *ListCitiesData:
+		message.Data = &pb.Request_ListCities{ListCities: &pb.ListCitiesData{Limit: int32(data.Limit), Cursor: data.Cursor}}
+	case SearchStoresData:
+		message.Data = &pb.Request_SearchStores{SearchStores: &pb.SearchStoresData{Query: data.Query, Limit: int32(data.Limit)}}
+	case *SearchStoresData:
+		message.Data = &pb.Request_SearchStores{SearchStores: &pb.SearchStoresData{Query: data.Query, Limit: int32(data.Limit)}}
+	default:
+		return nil, fmt.Errorf("no protobuf message for request payload %T", request.Payload)
+	}
+
+	return message, nil
+}
+
+func requestFromProto(message *pb.Request) (Request, error) {
+	request := Request{
+		SchemaVersion:  int(message.GetSchemaVersion()),
+		Action:         message.GetAction(),
+		StoreID:        message.GetStoreId(),
+		UserLogin:      message.GetUserLogin(),
+		VersionID:      message.GetVersionId(),
+		IdempotencyKey: message.GetIdempotencyKey(),
+	}
+
+	var payload interface{}
+	switch data := message.GetData().(type) {
+	case *pb.Request_CreateStore:
+		payload = CreateStoreData{
+			Name:        data.CreateStore.GetName(),
+			Address:     data.CreateStore.GetAddress(),
+			OwnerName:   data.CreateStore.GetOwnerName(),
+			OpeningTime: data.CreateStore.GetOpeningTime(),
+			ClosingTime: data.CreateStore.GetClosingTime(),
+
+			StructuredAddress: structuredAddressFromProto(data.CreateStore.GetStructuredAddress()),
+		}
+	case *pb.Request_CreateStoreVersion:
+		payload = CreateStoreVersionData{
+			Name:        data.CreateStoreVersion.GetName(),
+			Address:     data.CreateStoreVersion.GetAddress(),
+			OwnerName:   data.CreateStoreVersion.GetOwnerName(),
+			OpeningTime: data.CreateStoreVersion.GetOpeningTime(),
+			ClosingTime: data.CreateStoreVersion.GetClosingTime(),
+
+			StructuredAddress: structuredAddressFromProto(data.CreateStoreVersion.GetStructuredAddress()),
+			ExpectedRevisions: data.CreateStoreVersion.GetExpectedRevisions(),
+			IfMatchAny:        data.CreateStoreVersion.GetIfMatchAny(),
+		}
+	case *pb.Request_StoreDiff:
+		payload = StoreDiffData{
+			FromVersionID: data.StoreDiff.GetFromVersionId(),
+			ToVersionID:   data.StoreDiff.GetToVersionId(),
+			Compact:       data.StoreDiff.GetCompact(),
+		}
+	case *pb.Request_PointInTime:
+		payload = PointInTimeData{At: data.PointInTime.GetAt()}
+	case *pb.Request_ListStores:
+		payload = ListStoresData{
+			CreatorLogin: data.ListStores.GetCreatorLogin(),
+			NamePrefix:   data.ListStores.GetNamePrefix(),
+			City:         data.ListStores.GetCity(),
+			CreatedFrom:  data.ListStores.GetCreatedFrom(),
+			CreatedTo:    data.ListStores.GetCreatedTo(),
+			Sort:         data.ListStores.GetSort(),
+			Order:        data.ListStores.GetOrder(),
+			Limit:        int(data.ListStores.GetLimit()),
+			Cursor:       data.ListStores.GetCursor(),
+		}
+	case *pb.Request_StoreHistory:
+		payload = StoreHistoryData{
+			At:           data.StoreHistory.GetAt(),
+			CreatorLogin: data.StoreHistory.GetCreatorLogin(),
+			CreatedFrom:  data.StoreHistory.GetCreatedFrom(),
+			CreatedTo:    data.StoreHistory.GetCreatedTo(),
+			Order:        data.StoreHistory.GetOrder(),
+			Limit:        int(data.StoreHistory.GetLimit()),
+			Cursor:       data.StoreHistory.GetCursor(),
+		}
+	case *pb.Request_ListCities:
+		payload = ListCitiesData{
+			Limit:  int(data.ListCities.GetLimit()),
+			Cursor: data.ListCities.GetCursor(),
+		}
+	case *pb.Request_SearchStores:
+		payload = SearchStoresData{
+			Query: data.SearchStores.GetQuery(),
+			Limit: int(data.SearchStores.GetLimit()),
+		}
+	}
+
+	if payload != nil {
+		data, err := json.Marshal(payload)
+		if err != nil {
+			return Request{}, err
+		}
+		request.Data = data
+	}
+
+	return request, nil
+}
+
+// replyToProto converts the reply, its payload must be one of the reply data structs
+func replyToProto(reply Reply) (*pb.Reply, error) {
+	message := &pb.Reply{
+		RequestId: reply.RequestID,
+		Status:    reply.Status,
+		Code:      reply.Code,
+		Message:   reply.Message,
+	}
+
+	for _, detail := range reply.Details {
+		message.Details = append(message.Details, &pb.FieldError{Field: detail.Field, Message: detail.Message})
+	}
+
+	switch data := reply.Payload.(type) {
+	case nil:
+	case Store:
+		message.Data = &pb.Reply_Store{Store: storeToProto(data)}
+	case *Store:
+		message.Data = &pb.Reply_Store{Store: storeToProto(*data)}
+	case StoreList:
+		message.Data = &pb.Reply_Stores{Stores: storeListToProto(data)}
+	case *StoreList:
+		message.Data = &pb.Reply_Stores{Stores: storeListToProto(*data)}
+	case CityList:
+		message.Data = &pb.Reply_Cities{Cities: cityListToProto(data)}
+	case *CityList:
+		message.Data = &pb.Reply_Cities{Cities: cityListToProto(*data)}
+	case StoreVersion:
+		message.Data = &pb.Reply_StoreVersion{StoreVersion: storeVersionToProto(data)}
+	case *StoreVersion:
+		message.Data = &pb.Reply_StoreVersion{StoreVersion: storeVersionToProto(*data)}
+	case StoreVersionList:
+		message.Data = &pb.Reply_StoreVersions{StoreVersions: storeVersionListToProto(data)}
+	case *StoreVersionList:
+		message.Data = &pb.Reply_StoreVersions{StoreVersions: storeVersionListToProto(*data)}
+	case StoreDiff:
+		message.Data = &pb.Reply_StoreDiff{StoreDiff: storeDiffToProto(data)}
+	case *StoreDiff:
+		message.Data = &pb.Reply_StoreDiff{StoreDiff: storeDiffToProto(*data)}
+	case []StoreDiff:
+		diffs := &pb.StoreDiffList{}
+		for _, diff := range data {
+			diffs.Diffs = append(diffs.Diffs, storeDiffToProto(diff))
+		}
+		message.Data = &pb.Reply_StoreDiffs{StoreDiffs: diffs}
+	case []StoreSearchResult:
+		results := &pb.StoreSearchResultList{}
+		for _, result := range data {
+			results.Results = append(results.Results, storeSearchResultToProto(result))
+		}
+		message.Data = &pb.Reply_StoreSearchResults{StoreSearchResults: results}
+	default:
+		return nil, fmt.Errorf("no protobuf message for reply payload %T", reply.Payload)
+	}
+
+	return message, nil
+}
+
+func replyFromProto(message *pb.Reply) (*Reply, error) {
+	reply := &Reply{
+		RequestID: message.GetRequestId(),
+		Status:    message.GetStatus(),
+		Code:      message.GetCode(),
+		Message:   message.GetMessage(),
+	}
+
+	for _, detail := range message.GetDetails() {
+		reply.Details = append(reply.Details, FieldError{Field: detail.GetField(), Message: detail.GetMessage()})
+	}
+
+	var payload interface{}
+	switch data := message.GetData().(type) {
+	case *pb.Reply_Store:
+		payload = storeFromProto(data.Store)
+	case *pb.Reply_Stores:
+		stores := StoreList{
+			Stores:     make([]Store, 0, len(data.Stores.GetStores())),
+			NextCursor: data.Stores.GetNextCursor(),
+		}
+		for _, store := range data.Stores.GetStores() {
+			stores.Stores = append(stores.Stores, storeFromProto(store))
+		}
+		payload = stores
+	case *pb.Reply_Cities:
+		cities := CityList{
+			Cities:     make([]City, 0, len(data.Cities.GetCities())),
+			NextCursor: data.Cities.GetNextCursor(),
+		}
+		for _, city := range data.Cities.GetCities() {
+			cities.Cities = append(cities.Cities, City{Name: city.GetName(), Stores: int(city.GetStores())})
+		}
+		payload = cities
+	case *pb.Reply_StoreVersion:
+		payload = storeVersionFromProto(data.StoreVersion)
+	case *pb.Reply_StoreVersions:
+		versions := StoreVersionList{
+			Versions:   make([]StoreVersion, 0, len(data.StoreVersions.GetVersions())),
+			NextCursor: data.StoreVersions.GetNextCursor(),
+		}
+		for _, version := range data.StoreVersions.GetVersions() {
+			versions.Versions = append(versions.Versions, storeVersionFromProto(version))
+		}
+		payload = versions
+	case *pb.Reply_StoreDiff:
+		payload = storeDiffFromProto(data.StoreDiff)
+	case *pb.Reply_StoreDiffs:
+		diffs := make([]StoreDiff, 0, len(data.StoreDiffs.GetDiffs()))
+		for _, diff := range data.StoreDiffs.GetDiffs() {
+			diffs = append(diffs, storeDiffFromProto(diff))
+		}
+		payload = diffs
+	case *pb.Reply_StoreSearchResults:
+		results := make([]StoreSearchResult, 0, len(data.StoreSearchResults.GetResults()))
+		for _, result := range data.StoreSearchResults.GetResults() {
+			results = append(results, storeSearchResultFromProto(result))
+		}
+		payload = results
+	}
+
+	if payload != nil {
+		data, err := json.Marshal(payload)
+		if err != nil {
+			return nil, err
+		}
+		reply.Data = data
+	}
+
+	return reply, nil
+}
+
+func createStoreDataToProto(data CreateStoreData) *pb.CreateStoreData {
+	return &pb.CreateStoreData{
+		Name:        data.Name,
+		Address:     data.Address,
+		OwnerName:   data.OwnerName,
+		OpeningTime: data.OpeningTime,
+		ClosingTime: data.ClosingTime,
+
+		StructuredAddress: structuredAddressToProto(data.StructuredAddress),
+	}
+}
+
+func createStoreVersionDataToProto(data CreateStoreVersionData) *pb.CreateStoreVersionData {
+	return &pb.CreateStoreVersionData{
+		Name:        data.Name,
+		Address:     data.Address,
+		OwnerName:   data.OwnerName,
+		OpeningTime: data.OpeningTime,
+		ClosingTime: data.ClosingTime,
+
+		StructuredAddress: structuredAddressToProto(data.StructuredAddress),
+		ExpectedRevisions: data.ExpectedRevisions,
+		IfMatchAny:        data.IfMatchAny,
+	}
+}
+
+func storeDiffDataToProto(data StoreDiffData) *pb.StoreDiffData {
+	return &pb.StoreDiffData{
+		FromVersionId: data.FromVersionID,
+		ToVersionId:   data.ToVersionID,
+		Compact:       data.Compact,
+	}
+}
+
+func listStoresDataToProto(data ListStoresData) *pb.ListStoresData {
+	return &pb.ListStoresData{
+		CreatorLogin: data.CreatorLogin,
+		NamePrefix:   data.NamePrefix,
+		City:         data.City,
+		CreatedFrom:  data.CreatedFrom,
+		CreatedTo:    data.CreatedTo,
+		Sort:         data.Sort,
+		Order:        data.Order,
+		Limit:        int32(data.Limit),
+		Cursor:       data.Cursor,
+	}
+}
+
+func storeHistoryDataToProto(data StoreHistoryData) *pb.StoreHistoryData {
+	return &pb.StoreHistoryData{
+		At:           data.At,
+		CreatorLogin: data.CreatorLogin,
+		CreatedFrom:  data.CreatedFrom,
+		CreatedTo:    data.CreatedTo,
+		Order:        data.Order,
+		Limit:        int32(data.Limit),
+		Cursor:       data.Cursor,
+	}
+}
+
+func storeListToProto(list StoreList) *pb.StoreList {
+	message := &pb.StoreList{NextCursor: list.NextCursor}
+	for _, store := range list.Stores {
+		message.Stores = append(message.Stores, storeToProto(store))
+	}
+	return message
+}
+
+func cityListToProto(list CityList) *pb.CityList {
+	message := &pb.CityList{NextCursor: list.NextCursor}
+	for _, city := range list.Cities {
+		message.Cities = append(message.Cities, &pb.City{Name: city.Name, Stores: int32(city.Stores)})
+	}
+	return message
+}
+
+func storeToProto(store Store) *pb.Store {
+	return &pb.Store{
+		StoreId:      int64(store.StoreID),
+		Name:         store.Name,
+		Address:      store.Address,
+		CreatorLogin: store.CreatorLogin,
+		OwnerName:    store.OwnerName,
+		OpeningTime:  store.OpeningTime,
+		ClosingTime:  store.ClosingTime,
+		CreatedAt:    store.CreatedAt,
+
+		StructuredAddress:   structuredAddressToProto(&store.StructuredAddress),
+		LatestVersionNumber: int32(store.LatestVersionNumber),
+		Revision:            store.Revision,
+	}
+}
+
+func storeFromProto(store *pb.Store) Store {
+	return Store{
+		StoreID:      int(store.GetStoreId()),
+		Name:         store.GetName(),
+		Address:      store.GetAddress(),
+		CreatorLogin: store.GetCreatorLogin(),
+		OwnerName:    store.GetOwnerName(),
+		OpeningTime:  store.GetOpeningTime(),
+		ClosingTime:  store.GetClosingTime(),
+		CreatedAt:    store.GetCreatedAt(),
+
+		StructuredAddress:   addressFromProto(store.GetStructuredAddress()),
+		LatestVersionNumber: int(store.GetLatestVersionNumber()),
+		Revision:            store.GetRevision(),
+	}
+}
+
+func storeSearchResultToProto(result StoreSearchResult) *pb.StoreSearchResult {
+	return &pb.StoreSearchResult{
+		Store: storeToProto(result.Store),
+		Rank:  result.Rank,
+		Highlights: &pb.StoreHighlights{
+			Name:      result.Highlights.Name,
+			Address:   result.Highlights.Address,
+			OwnerName: result.Highlights.OwnerName,
+		},
+	}
+}
+
+func storeSearchResultFromProto(result *pb.StoreSearchResult) StoreSearchResult {
+	return StoreSearchResult{
+		Store: storeFromProto(result.GetStore()),
+		Rank:  result.GetRank(),
+		Highlights: StoreHighlights{
+			Name:      result.GetHighlights().GetName(),
+			Address:   result.GetHighlights().GetAddress(),
+			OwnerName: result.GetHighlights().GetOwnerName(),
+		},
+	}
+}
+
+func storeVersionListToProto(list StoreVersionList) *pb.StoreVersionList {
+	message := &pb.StoreVersionList{NextCursor: list.NextCursor}
+	for _, version := range list.Versions {
+		message.Versions = append(message.Versions, storeVersionToProto(version))
+	}
+	return message
+}
+
+func storeVersionToProto(version StoreVersion) *pb.StoreVersion {
+	return &pb.StoreVersion{
+		VersionId:     int64(version.VersionID),
+		StoreId:       version.StoreID,
+		VersionNumber: int32(version.VersionNumber),
+		CreatorLogin:  version.CreatorLogin,
+		Name:          version.Name,
+		Address:       version.Address,
+		OwnerName:     version.OwnerName,
+		OpeningTime:   version.OpeningTime,
+		ClosingTime:   version.ClosingTime,
+		CreatedAt:     version.CreatedAt,
+		IsLast:        version.IsLast,
+
+		StructuredAddress:     structuredAddressToProto(&version.StructuredAddress),
+		RestoredFromVersionId: int64(version.RestoredFromVersionID),
+		StoreRevision:         version.StoreRevision,
+	}
+}
+
+func storeVersionFromProto(version *pb.StoreVersion) StoreVersion {
+	return StoreVersion{
+		VersionID:     int(version.GetVersionId()),
+		StoreID:       version.GetStoreId(),
+		VersionNumber: int(version.GetVersionNumber()),
+		CreatorLogin:  version.GetCreatorLogin(),
+		Name:          version.GetName(),
+		Address:       version.GetAddress(),
+		OwnerName:     version.GetOwnerName(),
+		OpeningTime:   version.GetOpeningTime(),
+		ClosingTime:   version.GetClosingTime(),
+		CreatedAt:     version.GetCreatedAt(),
+		IsLast:        version.GetIsLast(),
+
+		StructuredAddress:     addressFromProto(version.GetStructuredAddress()),
+		RestoredFromVersionID: int(version.GetRestoredFromVersionId()),
+		StoreRevision:         version.GetStoreRevision(),
+	}
+}
+
+// structuredAddressToProto returns nil for a nil address
+func structuredAddressToProto(address *Address) *pb.Address {
+	if address == nil {
+		return nil
+	}
+
+	return &pb.Address{
+		City:       address.City,
+		Street:     address.Street,
+		House:      address.House,
+		PostalCode: address.PostalCode,
+		Unit:       address.Unit,
+	}
+}
+
+// structuredAddressFromProto returns nil for a missing address, request payloads tell it from an empty one
+func structuredAddressFromProto(address *pb.Address) *Address {
+	if address == nil {
+		return nil
+	}
+
+	structured := addressFromProto(address)
+	return &structured
+}
+
+func addressFromProto(address *pb.Address) Address {
+	return Address{
+		City:       address.GetCity(),
+		Street:     address.GetStreet(),
+		House:      address.GetHouse(),
+		PostalCode: address.GetPostalCode(),
+		Unit:       address.GetUnit(),
+	}
+}
+
+func storeDiffToProto(diff StoreDiff) *pb.StoreDiff {
+	message := &pb.StoreDiff{
+		StoreId: diff.StoreID,
+		From:    storeVersionRefToProto(diff.From),
+		To:      storeVersionRefToProto(diff.To),
+	}
+
+	for _, change := range diff.Changes {
+		message.Changes = append(message.Changes, &pb.FieldChange{
+			Field:   change.Field,
+			Before:  change.Before,
+			After:   change.After,
+			Changed: change.Changed,
+		})
+	}
+
+	return message
+}
+
+func storeDiffFromProto(message *pb.StoreDiff) StoreDiff {
+	diff := StoreDiff{
+		StoreID: message.GetStoreId(),
+		From:    storeVersionRefFromProto(message.GetFrom()),
+		To:      storeVersionRefFromProto(message.GetTo()),
+		Changes: make([]FieldChange, 0, len(message.GetChanges())),
+	}
+
+	for _, change := range message.GetChanges() {
+		diff.Changes = append(diff.Changes, FieldChange{
+			Field:   change.GetField(),
+			Before:  change.GetBefore(),
+			After:   change.GetAfter(),
+			Changed: change.GetChanged(),
+		})
+	}
+
+	return diff
+}
+
+func storeVersionRefToProto(ref StoreVersionRef) *pb.StoreVersionRef {
+	return &pb.StoreVersionRef{
+		VersionId:     int64(ref.VersionID),
+		VersionNumber: int32(ref.VersionNumber),
+		CreatorLogin:  ref.CreatorLogin,
+		CreatedAt:     ref.CreatedAt,
+	}
+}
+
+func storeVersionRefFromProto(ref *pb.StoreVersionRef) StoreVersionRef {
+	return StoreVersionRef{
+		VersionID:     int(ref.GetVersionId()),
+		VersionNumber: int(ref.GetVersionNumber()),
+		CreatorLogin:  ref.GetCreatorLogin(),
+		CreatedAt:     ref.GetCreatedAt(),
+	}
+}
@@ -0,0 +1,79 @@
+package contract
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+// TestRequestRoundTrip checks that payloads survive protobuf encoding the same as JSON encoding
+func TestRequestRoundTrip(t *testing.T) {
+	payloads := map[string]interface{}{
+		ActionCreateStoreVersion: CreateStoreVersionData{
+			OwnerName:         "Doe, John",
+			StructuredAddress: &Address{City: "Karaganda", Street: "Lenina", House: "143"},
+			ExpectedRevisions: []int64{3, 5},
+		},
+		ActionGetStoreHistory: StoreHistoryData{At: "2024-01-01T00:00:00Z", Limit: 5},
+		ActionListStores:      ListStoresData{City: "Karaganda", Sort: StoreSortName},
+		ActionSearchStores:    SearchStoresData{Query: "bakery", Limit: 10},
+		ActionGetStoreDiff:    StoreDiffData{Compact: true},
+	}
+
+	for action, payload := range payloads {
+		request := Request{Action: action, StoreID: "1", Payload: payload}
+
+		fromJSON := decodeRequestData(t, request, ContentTypeJSON, payload)
+		fromProtobuf := decodeRequestData(t, request, ContentTypeProtobuf, payload)
+		if !reflect.DeepEqual(fromJSON, fromProtobuf) {
+			t.Errorf("%s: JSON gives %+v, protobuf gives %+v", action, fromJSON, fromProtobuf)
+		}
+	}
+}
+
+func TestReplyRoundTrip(t *testing.T) {
+	payloads := []interface{}{
+		Store{StoreID: 1, Name: "Bakery", LatestVersionNumber: 4, Revision: 7},
+		StoreVersion{VersionID: 2, StoreID: "1", VersionNumber: 4, StoreRevision: 7},
+		StoreList{Stores: []Store{{StoreID: 1}}, NextCursor: "c"},
+		StoreVersionList{Versions: []StoreVersion{{VersionID: 2}}, NextCursor: "c"},
+	}
+
+	for _, payload := range payloads {
+		body, err := EncodeReply(Reply{Status: StatusSuccess, Payload: payload}, ContentTypeProtobuf)
+		if err != nil {
+			t.Fatalf("%T: encode: %v", payload, err)
+		}
+
+		reply, err := DecodeReply(body, ContentTypeProtobuf)
+		if err != nil {
+			t.Fatalf("%T: decode: %v", payload, err)
+		}
+
+		want, _ := json.Marshal(payload)
+		if string(reply.Data) != string(want) {
+			t.Errorf("%T: got %s, want %s", payload, reply.Data, want)
+		}
+	}
+}
+
+func decodeRequestData(t *testing.T, request Request, contentType string, payload interface{}) interface{} {
+	t.Helper()
+
+	body, err := EncodeRequest(request, contentType)
+	if err != nil {
+		t.Fatalf("%s %s: encode: %v", contentType, request.Action, err)
+	}
+
+	decoded, err := DecodeRequest(body, contentType)
+	if err != nil {
+		t.Fatalf("%s %s: decode: %v", contentType, request.Action, err)
+	}
+
+	data := reflect.New(reflect.TypeOf(payload))
+	if err = decoded.DecodeData(data.Interface()); err != nil {
+		t.Fatalf("%s %s: decode data: %v", contentType, request.Action, err)
+	}
+
+	return data.Elem().Interface()
+}
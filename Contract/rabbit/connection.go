@@ -0,0 +1,202 @@
+package rabbit
+
+import (
+	"errors"
+	"github.com/streadway/amqp"
+	"go.uber.org/zap"
+	"sync"
+	"time"
+)
+
+var ErrNotConnected = errors.New("not connected to RabbitMQ")
+
+// SetupFunc runs on every (re)connect. It declares the topology and starts consumers
+// on the fresh connection, the channel is shared by publishers
+type SetupFunc func(conn *amqp.Connection, channel *amqp.Channel) error
+
+// ConnectionManager keeps a RabbitMQ connection alive. When the connection or its channel
+// is closed it reconnects with exponential backoff and runs the setup functions again
+type ConnectionManager struct {
+	url        string
+	minBackoff time.Duration
+	maxBackoff time.Duration
+	logger     *zap.Logger
+	setups     []SetupFunc
+
+	mu      sync.RWMutex
+	conn    *amqp.Connection
+	channel *amqp.Channel
+	ready   bool
+
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+func NewConnectionManager(url string, minBackoff, maxBackoff time.Duration, logger *zap.Logger) *ConnectionManager {
+	return &ConnectionManager{
+		url:        url,
+		minBackoff: minBackoff,
+		maxBackoff: maxBackoff,
+		logger:     logger.With(zap.String("place", "ConnectionManager")),
+		done:       make(chan struct{}),
+	}
+}
+
+// OnConnect registers a setup function. Functions run in registration order, so register them before Start
+func (m *ConnectionManager) OnConnect(setup SetupFunc) {
+	m.setups = append(m.setups, setup)
+}
+
+// Start blocks until the first connection is established and then supervises it in the background
+func (m *ConnectionManager) Start() error {
+	if !m.connect() {
+		return ErrNotConnected
+	}
+
+	go m.supervise()
+
+	return nil
+}
+
+// IsReady reports whether the connection is up and the setup functions succeeded
+func (m *ConnectionManager) IsReady() bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return m.ready
+}
+
+// Publish sends the message through the shared channel of the current connection
+func (m *ConnectionManager) Publish(exchange, key string, mandatory, immediate bool, msg amqp.Publishing) error {
+	m.mu.RLock()
+	channel, ready := m.channel, m.ready
+	m.mu.RUnlock()
+
+	if !ready {
+		return ErrNotConnected
+	}
+
+	return channel.Publish(exchange, key, mandatory, immediate, msg)
+}
+
+// Channel opens a new channel on the current connection. The caller closes it
+func (m *ConnectionManager) Channel() (*amqp.Channel, error) {
+	m.mu.RLock()
+	conn, ready := m.conn, m.ready
+	m.mu.RUnlock()
+
+	if !ready {
+		return nil, ErrNotConnected
+	}
+
+	return conn.Channel()
+}
+
+// Close stops reconnecting and closes the current connection
+func (m *ConnectionManager) Close() error {
+	m.closeOnce.Do(func() {
+		close(m.done)
+	})
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.ready = false
+	if m.conn == nil || m.conn.IsClosed() {
+		return nil
+	}
+
+	return m.conn.Close()
+}
+
+func (m *ConnectionManager) supervise() {
+	for {
+		m.mu.RLock()
+		conn, channel := m.conn, m.channel
+		m.mu.RUnlock()
+
+		connClosed := conn.NotifyClose(make(chan *amqp.Error, 1))
+		channelClosed := channel.NotifyClose(make(chan *amqp.Error, 1))
+
+		select {
+		case <-m.done:
+			return
+		case err := <-connClosed:
+			m.logger.With(zap.Any("reason", err)).Error("RabbitMQ connection closed")
+		case err := <-channelClosed:
+			m.logger.With(zap.Any("reason", err)).Error("RabbitMQ channel closed")
+			_ = conn.Close()
+		}
+
+		m.mu.Lock()
+		m.ready = false
+		m.mu.Unlock()
+
+		if !m.connect() {
+			return
+		}
+	}
+}
+
+// connect dials until it succeeds or the manager is closed
+func (m *ConnectionManager) connect() bool {
+	backoff := m.minBackoff
+
+	for {
+		select {
+		case <-m.done:
+			return false
+		default:
+		}
+
+		err := m.dial()
+		if err == nil {
+			m.logger.Info("Connected to RabbitMQ")
+			return true
+		}
+
+		m.logger.With(
+			zap.Duration("retryIn", backoff),
+			zap.Error(err),
+		).Error("Failed to connect to RabbitMQ")
+
+		select {
+		case <-m.done:
+			return false
+		case <-time.After(backoff):
+		}
+
+		backoff *= 2
+		if backoff > m.maxBackoff {
+			backoff = m.maxBackoff
+		}
+	}
+}
+
+func (m *ConnectionManager) dial() error {
+	conn, err := amqp.Dial(m.url)
+	if err != nil {
+		return err
+	}
+
+	channel, err := conn.Channel()
+	if err != nil {
+		_ = conn.Close()
+		return err
+	}
+
+	for _, setup := range m.setups {
+		if err = setup(conn, channel); err != nil {
+			_ = conn.Close()
+			return err
+		}
+	}
+
+	m.mu.Lock()
+	m.conn = conn
+	m.channel = channel
+	m.ready = true
+	m.mu.Unlock()
+
+	return nil
+}
@@ -0,0 +1,137 @@
+package rabbit
+
+import (
+	"github.com/streadway/amqp"
+	"time"
+)
+
+type Exchange struct {
+	Name    string
+	Kind    string
+	Durable bool
+}
+
+// QuorumQueue is the queue type that counts redeliveries of a message in the x-delivery-count header
+const QuorumQueue = "quorum"
+
+// Queue describes a work queue. An empty Type declares a classic queue
+type Queue struct {
+	Name        string        `mapstructure:"name"`
+	Type        string        `mapstructure:"type"`
+	BindingKeys []string      `mapstructure:"bindingKeys"`
+	Durable     bool          `mapstructure:"durable"`
+	AutoDelete  bool          `mapstructure:"autoDelete"`
+	MessageTTL  time.Duration `mapstructure:"messageTtl"`
+	MaxLength   int           `mapstructure:"maxLength"`
+}
+
+// Topology describes exchanges and queues shared by the gateway and the storage service.
+// Both services declare it, so the config must be the same in both
+type Topology struct {
+	Exchange           Exchange
+	Queues             []Queue
+	DeadLetterExchange string
+	DeadLetterQueue    string
+	Persistent         bool
+}
+
+// DeclareTopology declares the exchange, the work queues bound to it and the dead-letter exchange and queue
+func DeclareTopology(channel *amqp.Channel, topology *Topology) error {
+	err := declareDeadLetterTopology(channel, topology)
+	if err != nil {
+		return err
+	}
+
+	err = channel.ExchangeDeclare(
+		topology.Exchange.Name,    // name
+		topology.Exchange.Kind,    // type
+		topology.Exchange.Durable, // durable
+		false,                     // auto-deleted
+		false,                     // internal
+		false,                     // no-wait
+		nil,                       // arguments
+	)
+	if err != nil {
+		return err
+	}
+
+	for _, queue := range topology.Queues {
+		_, err = channel.QueueDeclare(
+			queue.Name,                      // name
+			queue.Durable,                   // durable
+			queue.AutoDelete,                // delete when unused
+			false,                           // exclusive
+			false,                           // no-wait
+			QueueArguments(topology, queue), // arguments
+		)
+		if err != nil {
+			return err
+		}
+
+		for _, key := range queue.BindingKeys {
+			err = channel.QueueBind(queue.Name, key, topology.Exchange.Name, false, nil)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// QueueArguments builds the work queue arguments. Zero TTL and max length mean no limit
+func QueueArguments(topology *Topology, queue Queue) amqp.Table {
+	args := amqp.Table{
+		"x-dead-letter-exchange":    topology.DeadLetterExchange,
+		"x-dead-letter-routing-key": topology.DeadLetterQueue,
+	}
+
+	if queue.Type != "" {
+		args["x-queue-type"] = queue.Type
+	}
+	if queue.MessageTTL > 0 {
+		args["x-message-ttl"] = queue.MessageTTL.Milliseconds()
+	}
+	if queue.MaxLength > 0 {
+		args["x-max-length"] = int64(queue.MaxLength)
+	}
+
+	return args
+}
+
+// DeliveryMode returns the delivery mode for messages published to the work queue
+func DeliveryMode(topology *Topology) uint8 {
+	if topology.Persistent {
+		return amqp.Persistent
+	}
+	return amqp.Transient
+}
+
+func declareDeadLetterTopology(channel *amqp.Channel, topology *Topology) error {
+	err := channel.ExchangeDeclare(
+		topology.DeadLetterExchange, // name
+		"direct",                    // type
+		true,                        // durable
+		false,                       // auto-deleted
+		false,                       // internal
+		false,                       // no-wait
+		nil,                         // arguments
+	)
+	if err != nil {
+		return err
+	}
+
+	_, err = channel.QueueDeclare(
+		topology.DeadLetterQueue, // name
+		true,                     // durable
+		false,                    // delete when unused
+		false,                    // exclusive
+		false,                    // no-wait
+		nil,                      // arguments
+	)
+	if err != nil {
+		return err
+	}
+
+	return channel.QueueBind(topology.DeadLetterQueue, topology.DeadLetterQueue, topology.DeadLetterExchange, false, nil)
+}
@@ -0,0 +1,35 @@
+package rabbit
+
+import (
+	"github.com/streadway/amqp"
+	"testing"
+	"time"
+)
+
+func TestQueueArguments(t *testing.T) {
+	topology := &Topology{DeadLetterExchange: "dlx", DeadLetterQueue: "dlq"}
+
+	args := QueueArguments(topology, Queue{Name: "reads"})
+	if len(args) != 2 || args["x-dead-letter-exchange"] != "dlx" || args["x-dead-letter-routing-key"] != "dlq" {
+		t.Errorf("unlimited queue: got %v", args)
+	}
+
+	args = QueueArguments(topology, Queue{Name: "writes", MessageTTL: 30 * time.Second, MaxLength: 100})
+	if args["x-message-ttl"] != int64(30000) || args["x-max-length"] != int64(100) {
+		t.Errorf("limited queue: got %v", args)
+	}
+
+	args = QueueArguments(topology, Queue{Name: "writes", Type: QuorumQueue})
+	if args["x-queue-type"] != "quorum" {
+		t.Errorf("quorum queue: got %v", args)
+	}
+}
+
+func TestDeliveryMode(t *testing.T) {
+	if mode := DeliveryMode(&Topology{Persistent: true}); mode != amqp.Persistent {
+		t.Errorf("persistent topology: got %d", mode)
+	}
+	if mode := DeliveryMode(&Topology{}); mode != amqp.Transient {
+		t.Errorf("transient topology: got %d", mode)
+	}
+}
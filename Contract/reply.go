@@ -0,0 +1,95 @@
+package contract
+
+import (
+	"Contract/pb"
+	"encoding/json"
+	"fmt"
+	"google.golang.org/protobuf/proto"
+)
+
+// Reply statuses
+const (
+	StatusSuccess = "success"
+	StatusError   = "error"
+)
+
+// Error codes of failed replies, the gateway picks a status code by them
+const (
+	CodeStoreNotFound       = "store_not_found"
+	CodeVersionNotFound     = "version_not_found"
+	CodePermissionDenied    = "permission_denied"
+	CodeBadRequest          = "bad_request"
+	CodeIdempotencyConflict = "idempotency_key_conflict"
+	CodeOnlyVersion         = "only_version"
+	CodePreconditionFailed  = "precondition_failed"
+	CodeUnknownAction       = "unknown_action"
+	CodeUnsupportedSchema   = "unsupported_schema_version"
+	CodeInternal            = "internal"
+)
+
+// Reply is sent back by the storage service. Details lists invalid fields of rejected requests
+type Reply struct {
+	RequestID string          `json:"requestId,omitempty"`
+	Status    string          `json:"status"`
+	Code      string          `json:"code,omitempty"`
+	Message   string          `json:"message,omitempty"`
+	Data      json.RawMessage `json:"data,omitempty"`
+	Details   []FieldError    `json:"details,omitempty"`
+
+	// Payload is encoded into Data by EncodeReply
+	Payload interface{} `json:"-"`
+}
+
+// FieldError points at a request field that failed validation
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// EncodeReply returns the message body in the content type
+func EncodeReply(reply Reply, contentType string) ([]byte, error) {
+	switch contentType {
+	case ContentTypeJSON:
+		if reply.Payload != nil {
+			data, err := json.Marshal(reply.Payload)
+			if err != nil {
+				return nil, err
+			}
+			reply.Data = data
+		}
+
+		return json.Marshal(reply)
+	case ContentTypeProtobuf:
+		message, err := replyToProto(reply)
+		if err != nil {
+			return nil, err
+		}
+
+		return proto.Marshal(message)
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrUnsupportedContentType, contentType)
+	}
+}
+
+// DecodeReply decodes the message body in the content type, an empty content type means JSON.
+// The payload is kept as JSON in Data
+func DecodeReply(body []byte, contentType string) (*Reply, error) {
+	switch contentType {
+	case "", ContentTypeJSON:
+		var reply Reply
+		if err := json.Unmarshal(body, &reply); err != nil {
+			return nil, err
+		}
+
+		return &reply, nil
+	case ContentTypeProtobuf:
+		var message pb.Reply
+		if err := proto.Unmarshal(body, &message); err != nil {
+			return nil, err
+		}
+
+		return replyFromProto(&message)
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrUnsupportedContentType, contentType)
+	}
+}
@@ -0,0 +1,197 @@
+package contract
+
+import (
+	"Contract/pb"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"google.golang.org/protobuf/proto"
+)
+
+// SchemaVersion is the version of the request envelope.
+// Requests without a version were sent before it was introduced and are read as version 1
+const SchemaVersion = 1
+
+// Request is the envelope of every message sent to the storage service.
+// IdempotencyKey is set by clients retrying writes, repeated writes with the same key return the first outcome
+type Request struct {
+	SchemaVersion  int             `json:"schemaVersion"`
+	Action         string          `json:"action"`
+	Data           json.RawMessage `json:"data,omitempty"`
+	StoreID        string          `json:"storeId,omitempty"`
+	UserLogin      string          `json:"userLogin"`
+	VersionID      string          `json:"versionId,omitempty"`
+	IdempotencyKey string          `json:"idempotencyKey,omitempty"`
+
+	// Payload is encoded into Data by EncodeRequest
+	Payload interface{} `json:"-"`
+}
+
+// CreateStoreData is the payload of ActionCreateStore. The address is given either as the legacy
+// "city, street, house" Address or as StructuredAddress, which wins when both are set
+type CreateStoreData struct {
+	Name        string `json:"name"`
+	Address     string `json:"address"`
+	OwnerName   string `json:"ownerName"`
+	OpeningTime string `json:"openingTime"`
+	ClosingTime string `json:"closingTime"`
+
+	StructuredAddress *Address `json:"structuredAddress,omitempty"`
+}
+
+// CreateStoreVersionData is the payload of ActionCreateStoreVersion. Empty fields keep the value
+// of the previous version, the address is given as in CreateStoreData. Non-empty ExpectedRevisions make the request fail with
+// CodePreconditionFailed unless the store revision is one of them, IfMatchAny unless the store exists and isn't deleted
+type CreateStoreVersionData struct {
+	Name        string `json:"name,omitempty"`
+	Address     string `json:"address,omitempty"`
+	OwnerName   string `json:"ownerName,omitempty"`
+	OpeningTime string `json:"openingTime,omitempty"`
+	ClosingTime string `json:"closingTime,omitempty"`
+
+	StructuredAddress *Address `json:"structuredAddress,omitempty"`
+	ExpectedRevisions []int64  `json:"expectedRevisions,omitempty"`
+	IfMatchAny        bool     `json:"ifMatchAny,omitempty"`
+}
+
+// PointInTimeData is the optional payload of ActionGetStore.
+// At is an RFC 3339 timestamp, the store is read as it was at that instant
+type PointInTimeData struct {
+	At string `json:"at"`
+}
+
+// StoreHistoryData is the optional payload of ActionGetStoreHistory, empty fields don't filter.
+// At, CreatedFrom and CreatedTo are RFC 3339 timestamps, versions are ordered by number, newest first
+// unless Order is OrderAsc. Cursor is the NextCursor of the previous page and must be sent with the same
+// filters and order. A PointInTimeData payload is read as a StoreHistoryData with At only
+type StoreHistoryData struct {
+	At           string `json:"at,omitempty"`
+	CreatorLogin string `json:"creatorLogin,omitempty"`
+	CreatedFrom  string `json:"createdFrom,omitempty"`
+	CreatedTo    string `json:"createdTo,omitempty"`
+	Order        string `json:"order,omitempty"`
+	Limit        int    `json:"limit,omitempty"`
+	Cursor       string `json:"cursor,omitempty"`
+}
+
+// Sort orders and page sizes of ActionListStores and ActionGetStoreHistory
+const (
+	StoreSortName      = "name"
+	StoreSortCreatedAt = "created_at"
+
+	OrderAsc  = "asc"
+	OrderDesc = "desc"
+
+	DefaultListLimit = 20
+	MaxListLimit     = 100
+)
+
+// ListStoresData is the payload of ActionListStores, empty fields don't filter.
+// CreatedFrom and CreatedTo are RFC 3339 timestamps, Cursor is the NextCursor of the previous page
+// and must be sent with the same filters and order
+type ListStoresData struct {
+	CreatorLogin string `json:"creatorLogin,omitempty"`
+	NamePrefix   string `json:"namePrefix,omitempty"`
+	City         string `json:"city,omitempty"`
+	CreatedFrom  string `json:"createdFrom,omitempty"`
+	CreatedTo    string `json:"createdTo,omitempty"`
+	Sort         string `json:"sort,omitempty"`
+	Order        string `json:"order,omitempty"`
+	Limit        int    `json:"limit,omitempty"`
+	Cursor       string `json:"cursor,omitempty"`
+}
+
+// ListCitiesData is the optional payload of ActionListCities. Cursor is the NextCursor of the previous page
+type ListCitiesData struct {
+	Limit  int    `json:"limit,omitempty"`
+	Cursor string `json:"cursor,omitempty"`
+}
+
+// SearchStoresData is the payload of ActionSearchStores. Query is free text of up to MaxSearchWords words
+// matched against the name, address and owner of the stores, Limit defaults to DefaultListLimit
+type SearchStoresData struct {
+	Query string `json:"query"`
+	Limit int    `json:"limit,omitempty"`
+}
+
+// MaxSearchWords bounds the words of a search query, each of them is looked up on its own
+const MaxSearchWords = 32
+
+// StoreDiffData is the payload of ActionGetStoreDiff. Compact requests ignore the version ids
+// and list the changed fields between every two consecutive versions of the store
+type StoreDiffData struct {
+	FromVersionID string `json:"fromVersionId,omitempty"`
+	ToVersionID   string `json:"toVersionId,omitempty"`
+	Compact       bool   `json:"compact,omitempty"`
+}
+
+// EncodeRequest sets the schema version and returns the message body in the content type
+func EncodeRequest(request Request, contentType string) ([]byte, error) {
+	request.SchemaVersion = SchemaVersion
+
+	switch contentType {
+	case ContentTypeJSON:
+		if request.Payload != nil {
+			data, err := json.Marshal(request.Payload)
+			if err != nil {
+				return nil, err
+			}
+			request.Data = data
+		}
+
+		return json.Marshal(request)
+	case ContentTypeProtobuf:
+		message, err := requestToProto(request)
+		if err != nil {
+			return nil, err
+		}
+
+		return proto.Marshal(message)
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrUnsupportedContentType, contentType)
+	}
+}
+
+// DecodeRequest decodes the message body in the content type, an empty content type means JSON.
+// The payload is kept as JSON in Data until DecodeData is called
+func DecodeRequest(body []byte, contentType string) (*Request, error) {
+	var request Request
+
+	switch contentType {
+	case "", ContentTypeJSON:
+		if err := json.Unmarshal(body, &request); err != nil {
+			return nil, err
+		}
+	case ContentTypeProtobuf:
+		var message pb.Request
+		if err := proto.Unmarshal(body, &message); err != nil {
+			return nil, err
+		}
+
+		var err error
+		if request, err = requestFromProto(&message); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrUnsupportedContentType, contentType)
+	}
+
+	if request.SchemaVersion == 0 {
+		request.SchemaVersion = SchemaVersion
+	}
+
+	return &request, nil
+}
+
+// HasData reports whether the request carries a payload
+func (r *Request) HasData() bool {
+	return len(r.Data) > 0 && string(r.Data) != "null"
+}
+
+// DecodeData decodes the payload into v, rejecting fields v doesn't have
+func (r *Request) DecodeData(v interface{}) error {
+	decoder := json.NewDecoder(bytes.NewReader(r.Data))
+	decoder.DisallowUnknownFields()
+
+	return decoder.Decode(v)
+}
@@ -0,0 +1,115 @@
+package contract
+
+// Address is a store address split into parts, PostalCode and Unit are optional.
+// The Address string of stores and versions is "city, street, house"
+type Address struct {
+	City       string `json:"city"`
+	Street     string `json:"street"`
+	House      string `json:"house"`
+	PostalCode string `json:"postalCode,omitempty"`
+	Unit       string `json:"unit,omitempty"`
+}
+
+// Store is the reply payload of ActionCreateStore and ActionGetStore. LatestVersionNumber is the number
+// of the last version. Revision grows with every change of the store, the gateway turns it into an ETag.
+// Stores read at a point in time have no revision. Creation times of stores and versions are RFC 3339 in UTC
+type Store struct {
+	StoreID      int    `json:"storeId"`
+	Name         string `json:"name"`
+	Address      string `json:"address"`
+	CreatorLogin string `json:"creatorLogin"`
+	OwnerName    string `json:"ownerName"`
+	OpeningTime  string `json:"openingTime"`
+	ClosingTime  string `json:"closingTime"`
+	CreatedAt    string `json:"createdAt"`
+
+	StructuredAddress   Address `json:"structuredAddress"`
+	LatestVersionNumber int     `json:"latestVersionNumber"`
+	Revision            int64   `json:"revision,omitempty"`
+}
+
+// StoreList is the reply payload of ActionListStores. NextCursor is empty on the last page
+type StoreList struct {
+	Stores     []Store `json:"stores"`
+	NextCursor string  `json:"nextCursor,omitempty"`
+}
+
+// CityList is the reply payload of ActionListCities, cities are in alphabetical order.
+// NextCursor is empty on the last page
+type CityList struct {
+	Cities     []City `json:"cities"`
+	NextCursor string `json:"nextCursor,omitempty"`
+}
+
+// City is a city with the number of live stores in it. Cities differing only in case are one city
+type City struct {
+	Name   string `json:"name"`
+	Stores int    `json:"stores"`
+}
+
+// StoreSearchResult is a store found by ActionSearchStores, which replies with a list of them, best match first.
+// The higher the Rank the better the store matches
+type StoreSearchResult struct {
+	Store      Store           `json:"store"`
+	Rank       float64         `json:"rank"`
+	Highlights StoreHighlights `json:"highlights"`
+}
+
+// StoreHighlights are the store fields with the matching words wrapped in <b></b>
+type StoreHighlights struct {
+	Name      string `json:"name"`
+	Address   string `json:"address"`
+	OwnerName string `json:"ownerName"`
+}
+
+// StoreVersionList is the reply payload of ActionGetStoreHistory. NextCursor is empty on the last page
+type StoreVersionList struct {
+	Versions   []StoreVersion `json:"versions"`
+	NextCursor string         `json:"nextCursor,omitempty"`
+}
+
+// StoreVersion is the reply payload of ActionCreateStoreVersion, ActionRestoreStoreVersion and
+// ActionGetStoreVersion.
+// RestoredFromVersionID is set for versions created by a restore. StoreRevision is the store revision
+// right after the version was created, it is only set in replies to create and restore
+type StoreVersion struct {
+	VersionID     int    `json:"versionId"`
+	StoreID       string `json:"storeId"`
+	VersionNumber int    `json:"versionNumber"`
+	CreatorLogin  string `json:"creatorLogin"`
+	Name          string `json:"name"`
+	Address       string `json:"address"`
+	OwnerName     string `json:"ownerName"`
+	OpeningTime   string `json:"openingTime"`
+	ClosingTime   string `json:"closingTime"`
+	CreatedAt     string `json:"createdAt"`
+	IsLast        bool   `json:"isLast"`
+
+	StructuredAddress     Address `json:"structuredAddress"`
+	RestoredFromVersionID int     `json:"restoredFromVersionId,omitempty"`
+	StoreRevision         int64   `json:"storeRevision,omitempty"`
+}
+
+// StoreDiff is the reply payload of ActionGetStoreDiff, compact requests get a list of them
+type StoreDiff struct {
+	StoreID string          `json:"storeId"`
+	From    StoreVersionRef `json:"from"`
+	To      StoreVersionRef `json:"to"`
+	Changes []FieldChange   `json:"changes"`
+}
+
+// StoreVersionRef tells which version a diff side is and who made it when
+type StoreVersionRef struct {
+	VersionID     int    `json:"versionId"`
+	VersionNumber int    `json:"versionNumber"`
+	CreatorLogin  string `json:"creatorLogin"`
+	CreatedAt     string `json:"createdAt"`
+}
+
+// FieldChange holds the values of a version field on both sides of a diff
+type FieldChange struct {
+	Field   string `json:"field"`
+	Before  string `json:"before"`
+	After   string `json:"after"`
+	Changed bool   `json:"changed"`
+}
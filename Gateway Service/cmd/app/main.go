@@ -1,23 +1,29 @@
 package main
 
 import (
+	"Contract"
+	"Contract/rabbit"
 	"GatewayService/internal/config"
 	"GatewayService/internal/handler"
 	"GatewayService/internal/handler/mapper"
 	"GatewayService/internal/handler/validation"
 	"GatewayService/internal/middleware"
+	"GatewayService/internal/migration"
 	"GatewayService/internal/provider"
 	"GatewayService/internal/repository"
 	"GatewayService/internal/server"
 	"GatewayService/internal/service"
 	"context"
+	"fmt"
 	"github.com/go-playground/validator/v10"
+	"github.com/jmoiron/sqlx"
 	"github.com/streadway/amqp"
 	"go.uber.org/zap"
 	"log"
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 )
 
 func main() {
@@ -47,6 +53,15 @@ func main() {
 		).Panic("failed to register validators")
 	}
 
+	db, err := initDB(cfg, migration.NewMigration(), logger)
+	if err != nil {
+		logger.With(
+			zap.String("place", "main"),
+			zap.Error(err),
+		).Panic("Failed to establish database connection")
+	}
+	defer db.Close()
+
 	providerCfg := cfg.GetAuthProviderConfig(logger)
 
 	authProvider, err := provider.NewAuthProvider(*providerCfg, logger)
@@ -57,28 +72,38 @@ func main() {
 		).Panic("failed to connect to auth provider")
 	}
 
-	rabbitConnection, err := initRabbitMQConnection(cfg)
+	mqConfig := cfg.GetRabbitMQConfig()
+	topology, err := cfg.GetRabbitTopologyConfig()
 	if err != nil {
 		logger.With(
 			zap.String("place", "main"),
 			zap.Error(err),
-		).Panic("Failed to establish RabbitMQ Connection")
+		).Panic("Failed to read RabbitMQ topology config")
 	}
 
-	channel, err := initRabbitChannel(rabbitConnection)
-	if err != nil {
+	if !contract.IsSupportedContentType(mqConfig.ContentType) {
 		logger.With(
 			zap.String("place", "main"),
-			zap.Error(err),
-		).Panic("Failed to init RabbitMQ channel")
+			zap.String("contentType", mqConfig.ContentType),
+		).Panic("Unsupported RabbitMQ message content type")
 	}
 
-	queueName, err := declareRabbitQueue(channel)
-	if err != nil {
+	connectionManager := rabbit.NewConnectionManager(cfg.GetAMQPConnectionURL(mqConfig), mqConfig.MinBackoff, mqConfig.MaxBackoff, logger)
+	defer connectionManager.Close()
+
+	storageProvider := provider.NewStorageProvider(connectionManager, topology, mqConfig.ContentType, mqConfig.ReplyTimeout, logger)
+
+	// runs on every (re)connect: the exclusive reply queue goes away with the connection
+	connectionManager.OnConnect(func(_ *amqp.Connection, channel *amqp.Channel) error {
+		return rabbit.DeclareTopology(channel, topology)
+	})
+	connectionManager.OnConnect(storageProvider.Setup)
+
+	if err = connectionManager.Start(); err != nil {
 		logger.With(
 			zap.String("place", "main"),
 			zap.Error(err),
-		).Panic("Failed to init RabbitMQ queue")
+		).Panic("Failed to establish RabbitMQ Connection")
 	}
 
 	userRepository := repository.NewMockUserRepository()
@@ -89,17 +114,28 @@ func main() {
 
 	authHandler := handler.NewAuthHandler(authService, logger, errorMapper)
 
-	storesHandler := handler.NewStoresHandler(channel, rabbitConnection, queueName, logger, structValidator)
+	jobRepository := repository.NewPostgresJobRepository(db, cfg.GetJobConfig(), logger)
+
+	jobService := service.NewJobService(logger, jobRepository)
+
+	storageErrorMapper := mapper.NewStorageErrorMapper()
+
+	storesHandler := handler.NewStoresHandler(storageProvider, jobService, logger, structValidator, storageErrorMapper)
+
+	storageProvider.HandleAsyncReplies(storesHandler.HandleResponse)
 
 	authMiddleware := middleware.NewMiddleware(authProvider)
 
-	router := handler.NewRouter(authHandler, storesHandler, authMiddleware)
+	healthHandler := handler.NewHealthHandler(connectionManager)
+
+	router := handler.NewRouter(authHandler, storesHandler, healthHandler, authMiddleware)
 
 	srvCfg := cfg.GetHTTPSrvConfig()
 
 	srv := server.NewServer(srvCfg, router, logger)
 
 	ctx, cancel := context.WithCancel(context.Background())
+	go jobRepository.Run(ctx)
 	go func() {
 		if err := srv.Run(ctx); err != nil {
 			logger.With(
@@ -133,28 +169,31 @@ func initLogger() (*zap.Logger, error) {
 	return logger, err
 }
 
-func declareRabbitQueue(channel *amqp.Channel) (string, error) {
-	queue, err := channel.QueueDeclare(
-		"CreateQueue", // name
-		false,         // durable
-		false,         // delete when unused
-		false,         // exclusive
-		false,         // no-wait
-		nil,           // arguments
-	)
-	return queue.Name, err
-}
-
-func initRabbitChannel(connection *amqp.Connection) (*amqp.Channel, error) {
-	channel, err := connection.Channel()
-
-	return channel, err
-}
+// initDB connects to the jobs database, retrying while Postgres starts, and migrates it
+func initDB(cfg *config.Configurator, migrator *migration.Migratory, logger *zap.Logger) (*sqlx.DB, error) {
+	dbCfg := cfg.DBConfig()
+
+	var err error
+	for i := 0; i < dbCfg.ReconnRetry; i++ {
+		var db *sqlx.DB
+		db, err = repository.ConnectToPostgresDB(dbCfg)
+		if err == nil {
+			if err = migrator.Migrate(db); err != nil {
+				_ = db.Close()
+				return nil, fmt.Errorf("migration failure: %w", err)
+			}
+
+			logger.Info("Connected to postgres, migrations done")
+			return db, nil
+		}
 
-func initRabbitMQConnection(cfg *config.Configurator) (*amqp.Connection, error) {
-	mqConfig := cfg.GetRabbitMQConfig()
+		logger.With(
+			zap.String("place", "main"),
+			zap.Error(err),
+		).Error("Failed to connect to db. Retrying")
 
-	conn, err := amqp.Dial(cfg.GetAMQPConnectionURL(mqConfig))
+		time.Sleep(dbCfg.TimeWaitPerTry)
+	}
 
-	return conn, err
+	return nil, err
 }
@@ -1,6 +1,7 @@
 package config
 
 import (
+	"Contract/rabbit"
 	"fmt"
 	"go.uber.org/zap"
 	"os"
@@ -10,10 +11,31 @@ import (
 )
 
 type RabbitMQConfig struct {
-	Host     string
-	Port     string
-	Username string
-	Password string
+	Host         string
+	Port         string
+	Username     string
+	Password     string
+	ReplyTimeout time.Duration
+	ContentType  string
+	MinBackoff   time.Duration
+	MaxBackoff   time.Duration
+}
+
+// JobConfig describes how long jobs are kept after their last update and how often expired ones are evicted
+type JobConfig struct {
+	TTL           time.Duration
+	EvictInterval time.Duration
+}
+
+// DB is the Postgres database jobs are kept in
+type DB struct {
+	Host           string
+	Port           string
+	Username       string
+	Password       string
+	DBName         string
+	ReconnRetry    int
+	TimeWaitPerTry time.Duration
 }
 
 type HTTPServerConfig struct {
@@ -56,15 +78,57 @@ func (cfg *Configurator) GetHTTPSrvConfig() *HTTPServerConfig {
 	}
 }
 
+func (cfg *Configurator) GetJobConfig() *JobConfig {
+	return &JobConfig{
+		TTL:           viper.GetDuration("jobs.ttl"),
+		EvictInterval: viper.GetDuration("jobs.evictInterval"),
+	}
+}
+
+func (cfg *Configurator) DBConfig() *DB {
+	return &DB{
+		Host:           viper.GetString("postgres.host"),
+		Port:           viper.GetString("postgres.port"),
+		Username:       viper.GetString("postgres.username"),
+		DBName:         viper.GetString("postgres.dbname"),
+		Password:       viper.GetString("postgres.password"),
+		ReconnRetry:    viper.GetInt("postgres.retry"),
+		TimeWaitPerTry: viper.GetDuration("postgres.timeWaitPerTry"),
+	}
+}
+
 func (cfg *Configurator) GetRabbitMQConfig() *RabbitMQConfig {
 	return &RabbitMQConfig{
-		Password: viper.GetString("rabbit.password"),
-		Username: viper.GetString("rabbit.username"),
-		Port:     viper.GetString("rabbit.port"),
-		Host:     viper.GetString("rabbit.host"),
+		Password:     viper.GetString("rabbit.password"),
+		Username:     viper.GetString("rabbit.username"),
+		Port:         viper.GetString("rabbit.port"),
+		Host:         viper.GetString("rabbit.host"),
+		ReplyTimeout: viper.GetDuration("rabbit.replyTimeout"),
+		ContentType:  viper.GetString("rabbit.contentType"),
+		MinBackoff:   viper.GetDuration("rabbit.reconnect.minBackoff"),
+		MaxBackoff:   viper.GetDuration("rabbit.reconnect.maxBackoff"),
 	}
 }
 
+func (cfg *Configurator) GetRabbitTopologyConfig() (*rabbit.Topology, error) {
+	var queues []rabbit.Queue
+	if err := viper.UnmarshalKey("rabbit.queues", &queues); err != nil {
+		return nil, fmt.Errorf("failed to read rabbit queues: %w", err)
+	}
+
+	return &rabbit.Topology{
+		Exchange: rabbit.Exchange{
+			Name:    viper.GetString("rabbit.exchange.name"),
+			Kind:    viper.GetString("rabbit.exchange.type"),
+			Durable: viper.GetBool("rabbit.exchange.durable"),
+		},
+		Queues:             queues,
+		DeadLetterExchange: viper.GetString("rabbit.deadLetter.exchange"),
+		DeadLetterQueue:    viper.GetString("rabbit.deadLetter.queue"),
+		Persistent:         viper.GetBool("rabbit.persistent"),
+	}, nil
+}
+
 func (cfg *Configurator) GetAMQPConnectionURL(rabbitCfg *RabbitMQConfig) string {
 	return fmt.Sprintf("amqp://%s:%s@%s:%s/", rabbitCfg.Username, rabbitCfg.Password, rabbitCfg.Host, rabbitCfg.Port)
 }
@@ -0,0 +1,61 @@
+package handler
+
+import (
+	"Contract"
+	"encoding/json"
+	"errors"
+)
+
+// Address is given either as the legacy "city, street, house" string, kept in Text,
+// or as an object with the parts. Parts can't contain commas, they are joined with them
+type Address struct {
+	Text       string `json:"-" validate:"omitempty,addressFormat"`
+	City       string `json:"city" validate:"required_without=Text,max=100,excludesall=0x2C"`
+	Street     string `json:"street" validate:"required_without=Text,max=100,excludesall=0x2C"`
+	House      string `json:"house" validate:"required_without=Text,max=100,excludesall=0x2C"`
+	PostalCode string `json:"postalCode" validate:"max=20"`
+	Unit       string `json:"unit" validate:"max=20"`
+}
+
+var errAddressType = errors.New("address must be a string or an object")
+
+func (a *Address) UnmarshalJSON(data []byte) error {
+	var text string
+	if err := json.Unmarshal(data, &text); err == nil {
+		*a = Address{Text: text}
+		return nil
+	}
+
+	// parts has the fields of Address without its UnmarshalJSON
+	type parts Address
+	var address parts
+	if err := json.Unmarshal(data, &address); err != nil {
+		return errAddressType
+	}
+
+	*a = Address(address)
+	return nil
+}
+
+func (a *Address) isEmpty() bool {
+	return *a == Address{}
+}
+
+// payload returns the address the way the contract carries it, the legacy string or the parts
+func (a *Address) payload() (string, *contract.Address) {
+	if a == nil {
+		return "", nil
+	}
+
+	if a.Text != "" {
+		return a.Text, nil
+	}
+
+	return "", &contract.Address{
+		City:       a.City,
+		Street:     a.Street,
+		House:      a.House,
+		PostalCode: a.PostalCode,
+		Unit:       a.Unit,
+	}
+}
@@ -0,0 +1,89 @@
+package handler
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+)
+
+// storeETagPrefix tells store ETags from other entity tags, e.g. "r12" for revision 12
+const storeETagPrefix = "r"
+
+var errInvalidIfMatch = errors.New("If-Match must be * or a list of entity tags")
+
+func storeETag(revision int64) string {
+	return `"` + storeETagPrefix + strconv.FormatInt(revision, 10) + `"`
+}
+
+// parseIfMatch reads an If-Match header as defined in RFC 9110, section 13.1.1. any is true for "*".
+// If-Match uses the strong comparison, so weak entity tags and tags that are not store ETags never match
+// and are left out of revisions
+func parseIfMatch(value string) (revisions []int64, any bool, err error) {
+	value = strings.TrimSpace(value)
+	if value == "*" {
+		return nil, true, nil
+	}
+
+	for value != "" {
+		weak := strings.HasPrefix(value, "W/")
+		if weak {
+			value = value[len("W/"):]
+		}
+
+		if !strings.HasPrefix(value, `"`) {
+			return nil, false, errInvalidIfMatch
+		}
+		end := strings.IndexByte(value[1:], '"')
+		if end < 0 {
+			return nil, false, errInvalidIfMatch
+		}
+		opaque := value[1 : end+1]
+		value = strings.TrimLeft(value[end+2:], " \t")
+
+		if !isETagOpaque(opaque) {
+			return nil, false, errInvalidIfMatch
+		}
+		if !weak {
+			if revision, ok := storeRevision(opaque); ok {
+				revisions = append(revisions, revision)
+			}
+		}
+
+		if value == "" {
+			break
+		}
+		if value[0] != ',' {
+			return nil, false, errInvalidIfMatch
+		}
+		// empty list elements are allowed by the list syntax
+		value = strings.TrimLeft(value, ", \t")
+	}
+
+	return revisions, false, nil
+}
+
+// storeRevision returns the revision of a store ETag without quotes
+func storeRevision(opaque string) (int64, bool) {
+	digits, ok := strings.CutPrefix(opaque, storeETagPrefix)
+	if !ok {
+		return 0, false
+	}
+
+	revision, err := strconv.ParseInt(digits, 10, 64)
+	if err != nil || revision < 1 || strconv.FormatInt(revision, 10) != digits {
+		return 0, false
+	}
+
+	return revision, true
+}
+
+// isETagOpaque checks the characters between the quotes of an entity tag
+func isETagOpaque(opaque string) bool {
+	for i := 0; i < len(opaque); i++ {
+		c := opaque[i]
+		if c == '"' || c < 0x21 || c == 0x7f {
+			return false
+		}
+	}
+	return true
+}
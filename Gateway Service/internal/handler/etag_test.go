@@ -0,0 +1,49 @@
+package handler
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseIfMatch(t *testing.T) {
+	tests := []struct {
+		value     string
+		revisions []int64
+		any       bool
+		invalid   bool
+	}{
+		{value: `*`, any: true},
+		{value: `"r3"`, revisions: []int64{3}},
+		{value: ` "r3" , "r5"`, revisions: []int64{3, 5}},
+		{value: `"r3",,"r5"`, revisions: []int64{3, 5}},
+		{value: `W/"r3"`},
+		{value: `W/"r3", "r4"`, revisions: []int64{4}},
+		{value: `"3"`},
+		{value: `"r03"`},
+		{value: `"r0"`},
+		{value: `"xyzzy", "r7"`, revisions: []int64{7}},
+		{value: `"a,b", "r7"`, revisions: []int64{7}},
+		{value: `r3`, invalid: true},
+		{value: `"r3`, invalid: true},
+		{value: `"r3" "r4"`, invalid: true},
+		{value: `"r 3"`, invalid: true},
+	}
+
+	for _, tt := range tests {
+		revisions, any, err := parseIfMatch(tt.value)
+		if (err != nil) != tt.invalid {
+			t.Errorf("%s: got error %v, want invalid %v", tt.value, err, tt.invalid)
+			continue
+		}
+		if any != tt.any || !reflect.DeepEqual(revisions, tt.revisions) {
+			t.Errorf("%s: got %v %v, want %v %v", tt.value, revisions, any, tt.revisions, tt.any)
+		}
+	}
+}
+
+func TestStoreETagRoundTrip(t *testing.T) {
+	revisions, _, err := parseIfMatch(storeETag(42))
+	if err != nil || !reflect.DeepEqual(revisions, []int64{42}) {
+		t.Fatalf("got %v %v, want [42]", revisions, err)
+	}
+}
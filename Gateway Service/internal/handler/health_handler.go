@@ -0,0 +1,31 @@
+package handler
+
+import (
+	"GatewayService/internal/handler/response"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+type ReadinessChecker interface {
+	IsReady() bool
+}
+
+type HealthHandler struct {
+	broker ReadinessChecker
+}
+
+func NewHealthHandler(broker ReadinessChecker) *HealthHandler {
+	return &HealthHandler{
+		broker: broker,
+	}
+}
+
+// Ready answers 503 while the RabbitMQ connection is down or being restored
+func (h *HealthHandler) Ready(c *gin.Context) {
+	if !h.broker.IsReady() {
+		c.JSON(http.StatusServiceUnavailable, response.BuildJSONResponse("Error", "RabbitMQ is not connected"))
+		return
+	}
+
+	c.JSON(http.StatusOK, response.BuildJSONResponse("Success", "Ready"))
+}
@@ -1,6 +1,7 @@
 package mapper
 
 import (
+	"GatewayService/internal/provider"
 	"GatewayService/internal/service"
 	"net/http"
 )
@@ -15,6 +16,12 @@ func NewAuthErrorMapper() ErrorMapper {
 	return mapper
 }
 
+func NewStorageErrorMapper() ErrorMapper {
+	storageErrMap := NewStorageErrMap()
+	mapper := ErrorMapper{mapper: storageErrMap}
+	return mapper
+}
+
 type ErrorInfo struct {
 	StatusCode int
 	Message    string
@@ -40,3 +47,18 @@ func NewAuthErrMap() ErrorMap {
 		service.ErrInvalidPassword: {StatusCode: http.StatusBadRequest, Message: "Wrong password provided"},
 	}
 }
+
+func NewStorageErrMap() ErrorMap {
+	return ErrorMap{
+		provider.ErrStoreNotFound:    {StatusCode: http.StatusNotFound, Message: "Store with provided id does not exist"},
+		provider.ErrVersionNotFound:  {StatusCode: http.StatusNotFound, Message: "Store version with provided id does not exist"},
+		provider.ErrPermissionDenied: {StatusCode: http.StatusForbidden, Message: "Only creator of the store can modify it"},
+		provider.ErrBadRequest:       {StatusCode: http.StatusBadRequest, Message: "Storage service rejected the request"},
+		provider.ErrReplyTimeout:     {StatusCode: http.StatusGatewayTimeout, Message: "Storage service did not reply in time"},
+		provider.ErrUnknownAction:    {StatusCode: http.StatusBadRequest, Message: "Storage service does not support the action"},
+		provider.ErrIdempotencyKey:   {StatusCode: http.StatusConflict, Message: "Idempotency key was already used for another request"},
+		provider.ErrOnlyVersion:      {StatusCode: http.StatusConflict, Message: "The only version of a store can't be deleted, delete the store instead"},
+		provider.ErrPrecondition:     {StatusCode: http.StatusPreconditionFailed, Message: "The store has changed since the ETag was issued, get it again"},
+		service.ErrJobNotFound:       {StatusCode: http.StatusNotFound, Message: "Job with provided id does not exist"},
+	}
+}
@@ -5,8 +5,11 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
-func NewRouter(authHandler *AuthHandler, storesHandler *StoresHandler, middleware *middleware.Middleware) *gin.Engine {
+func NewRouter(authHandler *AuthHandler, storesHandler *StoresHandler, healthHandler *HealthHandler, middleware *middleware.Middleware) *gin.Engine {
 	router := gin.Default()
+	router.Use(middleware.RequestID())
+
+	router.GET("/ready", healthHandler.Ready)
 
 	authGroup := router.Group("auth")
 	authGroup.POST("/login", authHandler.SingIn)
@@ -14,15 +17,19 @@ func NewRouter(authHandler *AuthHandler, storesHandler *StoresHandler, middlewar
 	storesGroup := router.Group("storage")
 	storesGroup.POST("/store", middleware.AccessTokenValidation(), storesHandler.CreateStore)
 	storesGroup.POST("/store/:id/version", middleware.AccessTokenValidation(), storesHandler.CreateStoreVersion)
+	storesGroup.POST("/store/:id/version/:versionId/restore", middleware.AccessTokenValidation(), storesHandler.RestoreStoreVersion)
 	storesGroup.DELETE("/store/:id", middleware.AccessTokenValidation(), storesHandler.DeleteStore)
 	storesGroup.DELETE("/store/:id/version/:versionId", middleware.AccessTokenValidation(), storesHandler.DeleteStoreVersion)
+	storesGroup.POST("/store/:id/undelete", middleware.AccessTokenValidation(), storesHandler.UndeleteStore)
+	storesGroup.POST("/store/:id/version/:versionId/undelete", middleware.AccessTokenValidation(), storesHandler.UndeleteStoreVersion)
+	storesGroup.GET("/stores", middleware.AccessTokenValidation(), storesHandler.ListStores)
+	storesGroup.GET("/stores/search", middleware.AccessTokenValidation(), storesHandler.SearchStores)
+	storesGroup.GET("/stores/cities", middleware.AccessTokenValidation(), storesHandler.ListCities)
 	storesGroup.GET("/store/:id", middleware.AccessTokenValidation(), storesHandler.GetStore)
 	storesGroup.GET("/store/:id/history", middleware.AccessTokenValidation(), storesHandler.GetStoreHistory)
+	storesGroup.GET("/store/:id/diff", middleware.AccessTokenValidation(), storesHandler.GetStoreDiff)
 	storesGroup.GET("/store/:id/version/:versionId", middleware.AccessTokenValidation(), storesHandler.GetStoreVersion)
-
-	//for response handling from storage service
-	responseGroup := router.Group("response")
-	responseGroup.POST("/", storesHandler.HandleResponse)
+	storesGroup.GET("/jobs/:id", middleware.AccessTokenValidation(), storesHandler.GetJob)
 
 	return router
 }
@@ -1,55 +1,106 @@
 package handler
 
 import (
+	"Contract"
+	"GatewayService/internal/handler/mapper"
 	"GatewayService/internal/handler/response"
 	"GatewayService/internal/handler/validation"
+	"GatewayService/internal/provider"
+	"GatewayService/internal/requestid"
+	"GatewayService/internal/service"
+	"context"
 	"encoding/json"
-	"fmt"
 	"github.com/gin-gonic/gin"
 	"github.com/go-playground/validator/v10"
-	"github.com/streadway/amqp"
 	"go.uber.org/zap"
 	"net/http"
+	"strconv"
+	"strings"
+	"time"
 )
 
+type StorageProvider interface {
+	Request(ctx context.Context, request contract.Request) (*contract.Reply, error)
+	Send(ctx context.Context, request contract.Request, correlationID string) error
+}
+
+type JobService interface {
+	CreateJob(login, action string) (*service.Job, error)
+	CompleteJob(id string, succeeded bool, result json.RawMessage, errorStatus int, errorMessage string) error
+	GetJob(id, login string) (*service.Job, error)
+}
+
 type StoresHandler struct {
 	logger          *zap.Logger
-	rabbitMQChannel *amqp.Channel
-	rabbitMQConn    *amqp.Connection
-	rabbitMQQueue   string
+	storageProvider StorageProvider
+	jobService      JobService
 	structValidator *validator.Validate
+	errorMapper     mapper.ErrorMapper
+}
+
+// Job is the state of an asynchronous request. ErrorStatus is the HTTP status code a failed request
+// would have been answered with, e.g. 412 when If-Match didn't match
+type Job struct {
+	ID          string          `json:"id"`
+	Action      string          `json:"action"`
+	Status      string          `json:"status"`
+	Result      json.RawMessage `json:"result,omitempty"`
+	Error       string          `json:"error,omitempty"`
+	ErrorStatus int             `json:"errorStatus,omitempty"`
+	CreatedAt   time.Time       `json:"createdAt"`
+	UpdatedAt   time.Time       `json:"updatedAt"`
 }
 
 // Some custom validators used
 type Store struct {
-	Name        string `json:"name" validate:"required,min=3,max=40"`
-	Address     string `json:"address" validate:"required,addressFormat"`
-	OwnerName   string `json:"ownerName" validate:"required,ownerNameFormat"`
-	OpeningTime string `json:"openingTime" validate:"required,timeFormat"`
-	ClosingTime string `json:"closingTime" validate:"required,timeFormat"`
+	Name        string   `json:"name" validate:"required,min=3,max=40"`
+	Address     *Address `json:"address" validate:"required"`
+	OwnerName   string   `json:"ownerName" validate:"required,ownerNameFormat"`
+	OpeningTime string   `json:"openingTime" validate:"required,timeFormat"`
+	ClosingTime string   `json:"closingTime" validate:"required,timeFormat"`
 }
 
+// StoreVersion fields left out keep the value of the previous version, at least one is required
 type StoreVersion struct {
-	OwnerName   string `json:"ownerName" validate:"required,ownerNameFormat"`
-	OpeningTime string `json:"openingTime" validate:"required,timeFormat"`
-	ClosingTime string `json:"closingTime" validate:"required,timeFormat"`
+	Name        string   `json:"name" validate:"omitempty,min=3,max=40"`
+	Address     *Address `json:"address" validate:"omitempty"`
+	OwnerName   string   `json:"ownerName" validate:"omitempty,ownerNameFormat"`
+	OpeningTime string   `json:"openingTime" validate:"omitempty,timeFormat"`
+	ClosingTime string   `json:"closingTime" validate:"omitempty,timeFormat"`
 }
 
-func NewStoresHandler(channel *amqp.Channel, rabbitMQConn *amqp.Connection, rabbitMQQueue string, logger *zap.Logger, structValidator *validator.Validate) *StoresHandler {
+func (v StoreVersion) isEmpty() bool {
+	return v.Name == "" && v.Address == nil && v.OwnerName == "" && v.OpeningTime == "" && v.ClosingTime == ""
+}
+
+const (
+	messageForError = "Failed to publish a message"
+
+	// idempotencyKeyHeader lets clients retry writes without creating duplicates
+	idempotencyKeyHeader    = "Idempotency-Key"
+	maxIdempotencyKeyLength = 255
+
+	// store ETags are made of the store revision, see storeETag
+	etagHeader    = "ETag"
+	ifMatchHeader = "If-Match"
+)
+
+// dateLayout is the last of pointInTimeLayouts, a day without a time
+const dateLayout = "2006-01-02"
+
+// pointInTimeLayouts are the formats accepted by timestamp query parameters, times without a zone are UTC
+var pointInTimeLayouts = []string{time.RFC3339, "2006-01-02 15:04:05", dateLayout}
+
+func NewStoresHandler(storageProvider StorageProvider, jobService JobService, logger *zap.Logger, structValidator *validator.Validate, errorMapper mapper.ErrorMapper) *StoresHandler {
 	return &StoresHandler{
 		logger:          logger,
-		rabbitMQChannel: channel,
-		rabbitMQConn:    rabbitMQConn,
-		rabbitMQQueue:   rabbitMQQueue,
+		storageProvider: storageProvider,
+		jobService:      jobService,
 		structValidator: structValidator,
+		errorMapper:     errorMapper,
 	}
 }
 
-const (
-	messageForError   = "Failed to publish a message"
-	messageForSuccess = "Storage service is processing your message. Check status through logs"
-)
-
 func (h *StoresHandler) CreateStore(c *gin.Context) {
 	var store Store
 	if err := c.ShouldBindJSON(&store); err != nil {
@@ -62,24 +113,31 @@ func (h *StoresHandler) CreateStore(c *gin.Context) {
 		return
 	}
 
-	action := "create_store"
-
-	login := c.GetString("login")
+	idempotencyKey, ok := h.idempotencyKey(c)
+	if !ok {
+		return
+	}
 
-	err := h.sendMessage(buildMessage(store, action, login, "", ""))
+	storeData := contract.CreateStoreData{
+		Name:        store.Name,
+		OwnerName:   store.OwnerName,
+		OpeningTime: store.OpeningTime,
+		ClosingTime: store.ClosingTime,
+	}
+	storeData.Address, storeData.StructuredAddress = store.Address.payload()
 
-	if err != nil {
-		h.logger.With(
-			zap.String("place", "Handler"),
-			zap.Error(err),
-		).Error("Failed to publish a message")
-		c.JSON(http.StatusInternalServerError, response.BuildJSONResponse("Error", messageForError))
-		return
+	message := contract.Request{
+		Action:         contract.ActionCreateStore,
+		Payload:        storeData,
+		UserLogin:      c.GetString("login"),
+		IdempotencyKey: idempotencyKey,
 	}
 
-	c.JSON(http.StatusOK, response.BuildJSONResponse("Success", messageForSuccess))
+	h.submit(c, message)
 }
 
+// CreateStoreVersion adds a version to the store. With If-Match the version is only added if the store
+// hasn't changed since one of the ETags was issued, or with "*" if it exists; a failed check fails the job with status 412
 func (h *StoresHandler) CreateStoreVersion(c *gin.Context) {
 	var storeVersion StoreVersion
 	if err := c.ShouldBindJSON(&storeVersion); err != nil {
@@ -87,185 +145,512 @@ func (h *StoresHandler) CreateStoreVersion(c *gin.Context) {
 		return
 	}
 
+	// an empty address keeps the previous one like a missing address
+	if storeVersion.Address != nil && storeVersion.Address.isEmpty() {
+		storeVersion.Address = nil
+	}
+
 	if err := h.structValidator.Struct(storeVersion); err != nil {
 		c.JSON(http.StatusBadRequest, validation.FormatValidatorError(err))
 		return
 	}
 
-	action := "create_store_version"
+	if storeVersion.isEmpty() {
+		c.JSON(http.StatusBadRequest, response.BuildJSONResponse("Error", "At least one store version field is required"))
+		return
+	}
 
-	login := c.GetString("login")
+	idempotencyKey, ok := h.idempotencyKey(c)
+	if !ok {
+		return
+	}
 
-	storeId := c.Param("id")
+	expectedRevisions, ifMatchAny, ok := h.ifMatch(c)
+	if !ok {
+		return
+	}
 
-	err := h.sendMessage(buildMessage(storeVersion, action, login, storeId, ""))
+	storeVersionData := contract.CreateStoreVersionData{
+		Name:        storeVersion.Name,
+		OwnerName:   storeVersion.OwnerName,
+		OpeningTime: storeVersion.OpeningTime,
+		ClosingTime: storeVersion.ClosingTime,
 
-	if err != nil {
-		h.logger.With(
-			zap.String("place", "Handler"),
-			zap.Error(err),
-		).Error("Failed to publish a message")
-		c.JSON(http.StatusInternalServerError, response.BuildJSONResponse("Error", messageForError))
-		return
+		ExpectedRevisions: expectedRevisions,
+		IfMatchAny:        ifMatchAny,
+	}
+	storeVersionData.Address, storeVersionData.StructuredAddress = storeVersion.Address.payload()
+
+	message := contract.Request{
+		Action:         contract.ActionCreateStoreVersion,
+		Payload:        storeVersionData,
+		StoreID:        c.Param("id"),
+		UserLogin:      c.GetString("login"),
+		IdempotencyKey: idempotencyKey,
+	}
+
+	h.submit(c, message)
+}
+
+// RestoreStoreVersion creates a new latest version of the store with the values of an older version
+func (h *StoresHandler) RestoreStoreVersion(c *gin.Context) {
+	message := contract.Request{
+		Action:    contract.ActionRestoreStoreVersion,
+		StoreID:   c.Param("id"),
+		VersionID: c.Param("versionId"),
+		UserLogin: c.GetString("login"),
 	}
 
-	c.JSON(http.StatusOK, response.BuildJSONResponse("Success", messageForSuccess))
+	h.submit(c, message)
 }
 
 func (h *StoresHandler) DeleteStore(c *gin.Context) {
-	action := "delete_store"
+	message := contract.Request{
+		Action:    contract.ActionDeleteStore,
+		StoreID:   c.Param("id"),
+		UserLogin: c.GetString("login"),
+	}
 
-	login := c.GetString("login")
+	h.submit(c, message)
+}
 
-	storeId := c.Param("id")
+func (h *StoresHandler) DeleteStoreVersion(c *gin.Context) {
+	message := contract.Request{
+		Action:    contract.ActionDeleteStoreVersion,
+		StoreID:   c.Param("id"),
+		VersionID: c.Param("versionId"),
+		UserLogin: c.GetString("login"),
+	}
 
-	err := h.sendMessage(buildMessage(nil, action, login, storeId, ""))
+	h.submit(c, message)
+}
 
-	if err != nil {
-		h.logger.With(
-			zap.String("place", "Handler"),
-			zap.Error(err),
-		).Error("Failed to publish a message")
-		c.JSON(http.StatusInternalServerError, response.BuildJSONResponse("Error", messageForError))
-		return
+// UndeleteStore brings back a deleted store that hasn't been purged yet
+func (h *StoresHandler) UndeleteStore(c *gin.Context) {
+	message := contract.Request{
+		Action:    contract.ActionUndeleteStore,
+		StoreID:   c.Param("id"),
+		UserLogin: c.GetString("login"),
 	}
 
-	c.JSON(http.StatusOK, response.BuildJSONResponse("Success", messageForSuccess))
+	h.submit(c, message)
 }
 
-func (h *StoresHandler) DeleteStoreVersion(c *gin.Context) {
-	action := "delete_store_version"
+// UndeleteStoreVersion brings back a deleted store version that hasn't been purged yet
+func (h *StoresHandler) UndeleteStoreVersion(c *gin.Context) {
+	message := contract.Request{
+		Action:    contract.ActionUndeleteStoreVersion,
+		StoreID:   c.Param("id"),
+		VersionID: c.Param("versionId"),
+		UserLogin: c.GetString("login"),
+	}
 
-	login := c.GetString("login")
+	h.submit(c, message)
+}
 
-	storeId := c.Param("id")
+// GetStore returns the store, with ?at= as it was at that time.
+// Current stores come with an ETag to send in If-Match when adding a version
+func (h *StoresHandler) GetStore(c *gin.Context) {
+	at, ok := h.pointInTime(c)
+	if !ok {
+		return
+	}
 
-	versionId := c.Param("versionId")
+	message := contract.Request{
+		Action:    contract.ActionGetStore,
+		Payload:   at,
+		StoreID:   c.Param("id"),
+		UserLogin: c.GetString("login"),
+	}
 
-	err := h.sendMessage(buildMessage(nil, action, login, storeId, versionId))
+	reply, ok := h.call(c, message)
+	if !ok {
+		return
+	}
 
-	if err != nil {
-		h.logger.With(
-			zap.String("place", "Handler"),
-			zap.Error(err),
-		).Error("Failed to publish a message")
-		c.JSON(http.StatusInternalServerError, response.BuildJSONResponse("Error", messageForError))
+	if at == nil {
+		var store contract.Store
+		if err := json.Unmarshal(reply.Data, &store); err == nil && store.Revision > 0 {
+			c.Header(etagHeader, storeETag(store.Revision))
+		}
+	}
+
+	h.writeReply(c, reply, http.StatusOK)
+}
+
+// GetStoreHistory returns a page of the versions of the store matching the query parameters, all of them optional.
+// With ?at= only versions created up to that time are listed. The nextCursor of the reply is passed as cursor,
+// with the same filters, to get the next page
+func (h *StoresHandler) GetStoreHistory(c *gin.Context) {
+	data := contract.StoreHistoryData{
+		CreatorLogin: c.Query("creatorLogin"),
+		Order:        c.Query("order"),
+		Cursor:       c.Query("cursor"),
+	}
+
+	var ok bool
+	if data.Limit, ok = h.limitQuery(c); !ok {
 		return
 	}
 
-	c.JSON(http.StatusOK, response.BuildJSONResponse("Success", messageForSuccess))
+	if data.At, ok = h.timestampQueryRFC3339(c, "at", false); !ok {
+		return
+	}
+	if data.CreatedFrom, ok = h.timestampQueryRFC3339(c, "createdFrom", false); !ok {
+		return
+	}
+	if data.CreatedTo, ok = h.timestampQueryRFC3339(c, "createdTo", true); !ok {
+		return
+	}
+
+	message := contract.Request{
+		Action:    contract.ActionGetStoreHistory,
+		Payload:   data,
+		StoreID:   c.Param("id"),
+		UserLogin: c.GetString("login"),
+	}
+
+	h.request(c, message, http.StatusOK)
 }
 
-func (h *StoresHandler) GetStore(c *gin.Context) {
-	action := "get_store"
+// GetStoreDiff compares the from and to versions of the store field by field.
+// With compact=true it lists the changed fields between every two consecutive versions instead
+func (h *StoresHandler) GetStoreDiff(c *gin.Context) {
+	compact := false
+	if value := c.Query("compact"); value != "" {
+		var err error
+		if compact, err = strconv.ParseBool(value); err != nil {
+			c.JSON(http.StatusBadRequest, response.BuildJSONResponse("Error", "compact must be true or false"))
+			return
+		}
+	}
 
-	login := c.GetString("login")
+	diffData := contract.StoreDiffData{Compact: compact}
+	if !compact {
+		diffData.FromVersionID = c.Query("from")
+		diffData.ToVersionID = c.Query("to")
 
-	storeId := c.Param("id")
+		if diffData.FromVersionID == "" || diffData.ToVersionID == "" {
+			c.JSON(http.StatusBadRequest, response.BuildJSONResponse("Error", "from and to query parameters are required"))
+			return
+		}
+	}
 
-	err := h.sendMessage(buildMessage(nil, action, login, storeId, ""))
+	message := contract.Request{
+		Action:    contract.ActionGetStoreDiff,
+		Payload:   diffData,
+		StoreID:   c.Param("id"),
+		UserLogin: c.GetString("login"),
+	}
 
-	if err != nil {
-		h.logger.With(
-			zap.String("place", "Handler"),
-			zap.Error(err),
-		).Error("Failed to publish a message")
-		c.JSON(http.StatusInternalServerError, response.BuildJSONResponse("Error", messageForError))
+	h.request(c, message, http.StatusOK)
+}
+
+func (h *StoresHandler) GetStoreVersion(c *gin.Context) {
+	message := contract.Request{
+		Action:    contract.ActionGetStoreVersion,
+		StoreID:   c.Param("id"),
+		VersionID: c.Param("versionId"),
+		UserLogin: c.GetString("login"),
+	}
+
+	h.request(c, message, http.StatusOK)
+}
+
+// ListStores returns a page of stores matching the query parameters, all of them optional.
+// The nextCursor of the reply is passed as cursor, with the same filters, to get the next page
+func (h *StoresHandler) ListStores(c *gin.Context) {
+	data := contract.ListStoresData{
+		CreatorLogin: c.Query("creatorLogin"),
+		NamePrefix:   c.Query("name"),
+		City:         c.Query("city"),
+		Sort:         c.Query("sort"),
+		Order:        c.Query("order"),
+		Cursor:       c.Query("cursor"),
+	}
+
+	var ok bool
+	if data.Limit, ok = h.limitQuery(c); !ok {
+		return
+	}
+
+	if data.CreatedFrom, ok = h.timestampQueryRFC3339(c, "createdFrom", false); !ok {
+		return
+	}
+	if data.CreatedTo, ok = h.timestampQueryRFC3339(c, "createdTo", true); !ok {
 		return
 	}
 
-	c.JSON(http.StatusOK, response.BuildJSONResponse("Success", messageForSuccess))
+	message := contract.Request{
+		Action:    contract.ActionListStores,
+		Payload:   data,
+		UserLogin: c.GetString("login"),
+	}
+
+	h.request(c, message, http.StatusOK)
 }
 
-func (h *StoresHandler) GetStoreHistory(c *gin.Context) {
+// ListCities returns a page of the cities with live stores in alphabetical order, each with its number of stores.
+// The nextCursor of the reply is passed as cursor to get the next page
+func (h *StoresHandler) ListCities(c *gin.Context) {
+	data := contract.ListCitiesData{Cursor: c.Query("cursor")}
 
-	action := "get_store_history"
+	var ok bool
+	if data.Limit, ok = h.limitQuery(c); !ok {
+		return
+	}
 
-	login := c.GetString("login")
+	message := contract.Request{
+		Action:    contract.ActionListCities,
+		Payload:   data,
+		UserLogin: c.GetString("login"),
+	}
 
-	storeId := c.Param("id")
+	h.request(c, message, http.StatusOK)
+}
+
+// SearchStores finds stores by the words of the q query parameter in their name, address or owner.
+// Results are ranked, best match first, and come with the matching words highlighted
+func (h *StoresHandler) SearchStores(c *gin.Context) {
+	data := contract.SearchStoresData{Query: strings.TrimSpace(c.Query("q"))}
+	if data.Query == "" {
+		c.JSON(http.StatusBadRequest, response.BuildJSONResponse("Error", "q query parameter is required"))
+		return
+	}
+
+	var ok bool
+	if data.Limit, ok = h.limitQuery(c); !ok {
+		return
+	}
+
+	message := contract.Request{
+		Action:    contract.ActionSearchStores,
+		Payload:   data,
+		UserLogin: c.GetString("login"),
+	}
 
-	err := h.sendMessage(buildMessage(nil, action, login, storeId, ""))
+	h.request(c, message, http.StatusOK)
+}
 
+func (h *StoresHandler) GetJob(c *gin.Context) {
+	job, err := h.jobService.GetJob(c.Param("id"), c.GetString("login"))
 	if err != nil {
-		h.logger.With(
-			zap.String("place", "Handler"),
-			zap.Error(err),
-		).Error("Failed to publish a message")
-		c.JSON(http.StatusInternalServerError, response.BuildJSONResponse("Error", messageForError))
+		errInf := h.errorMapper.MapError(err)
+
+		c.JSON(errInf.StatusCode, response.BuildJSONResponse("Error", errInf.Message))
 		return
 	}
-	c.JSON(http.StatusOK, response.BuildJSONResponse("Success", messageForSuccess))
+
+	c.JSON(http.StatusOK, response.BuildJSONResponse("Success", Job{
+		ID:          job.ID,
+		Action:      job.Action,
+		Status:      string(job.Status),
+		Result:      job.Result,
+		Error:       job.Error,
+		ErrorStatus: job.ErrorStatus,
+		CreatedAt:   job.CreatedAt,
+		UpdatedAt:   job.UpdatedAt,
+	}))
 }
 
-func (h *StoresHandler) GetStoreVersion(c *gin.Context) {
+// HandleResponse stores the outcome of an asynchronous job once the storage service replies
+func (h *StoresHandler) HandleResponse(jobID string, reply contract.Reply) {
+	logger := h.logger.With(
+		zap.String("place", "HandleResponse"),
+		zap.String("jobId", jobID),
+		zap.String("requestId", reply.RequestID),
+	)
 
-	action := "get_store_version"
+	logger.Info("Got reply from storage service for job")
 
-	login := c.GetString("login")
+	var err error
+	if replyErr := provider.ReplyError(&reply); replyErr != nil {
+		errInf := h.errorMapper.MapError(replyErr)
+		err = h.jobService.CompleteJob(jobID, false, nil, errInf.StatusCode, errInf.Message)
+	} else {
+		err = h.jobService.CompleteJob(jobID, true, reply.Data, 0, "")
+	}
 
-	storeId := c.Param("id")
+	if err != nil {
+		logger.With(zap.Error(err)).Error("Failed to complete job")
+	}
+}
 
-	versionId := c.Param("versionId")
+// idempotencyKey reads the optional Idempotency-Key header. It answers 400 and returns false for keys too long to store
+func (h *StoresHandler) idempotencyKey(c *gin.Context) (string, bool) {
+	key := c.GetHeader(idempotencyKeyHeader)
+	if len(key) > maxIdempotencyKeyLength {
+		c.JSON(http.StatusBadRequest, response.BuildJSONResponse("Error", "Idempotency-Key must not be longer than 255 characters"))
+		return "", false
+	}
 
-	err := h.sendMessage(buildMessage(nil, action, login, storeId, versionId))
+	return key, true
+}
+
+// pointInTime reads the optional at query parameter into the request payload, nil without it.
+// It answers 400 and returns false for timestamps in an unknown format
+func (h *StoresHandler) pointInTime(c *gin.Context) (interface{}, bool) {
+	at, ok := h.timestampQueryRFC3339(c, "at", false)
+	if !ok || at == "" {
+		return nil, ok
+	}
+
+	return contract.PointInTimeData{At: at}, true
+}
+
+// limitQuery reads the optional limit query parameter, 0 without it.
+// It answers 400 and returns false for limits out of range
+func (h *StoresHandler) limitQuery(c *gin.Context) (int, bool) {
+	value := c.Query("limit")
+	if value == "" {
+		return 0, true
+	}
+
+	limit, err := strconv.Atoi(value)
+	if err != nil || limit < 1 || limit > contract.MaxListLimit {
+		c.JSON(http.StatusBadRequest, response.BuildJSONResponse("Error",
+			"limit must be a number between 1 and "+strconv.Itoa(contract.MaxListLimit)))
+		return 0, false
+	}
+
+	return limit, true
+}
+
+// timestampQueryRFC3339 reads the optional query parameter like timestampQuery and formats it as RFC 3339,
+// empty without it. With endOfDay a date alone means the end of that day, so range ends include the whole day
+func (h *StoresHandler) timestampQueryRFC3339(c *gin.Context, name string, endOfDay bool) (string, bool) {
+	t, ok := h.timestampQuery(c, name)
+	if !ok || t.IsZero() {
+		return "", ok
+	}
+
+	if endOfDay && len(c.Query(name)) == len(dateLayout) {
+		t = t.Add(24*time.Hour - time.Second)
+	}
+
+	return t.Format(time.RFC3339), true
+}
+
+// timestampQuery reads the optional query parameter in one of pointInTimeLayouts, zero time without it.
+// It answers 400 and returns false for timestamps in an unknown format
+func (h *StoresHandler) timestampQuery(c *gin.Context, name string) (time.Time, bool) {
+	value := c.Query(name)
+	if value == "" {
+		return time.Time{}, true
+	}
+
+	for _, layout := range pointInTimeLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t, true
+		}
+	}
+
+	c.JSON(http.StatusBadRequest, response.BuildJSONResponse("Error",
+		name+" must be an RFC 3339 timestamp, YYYY-MM-DD HH:MM:SS or YYYY-MM-DD"))
+	return time.Time{}, false
+}
+
+// submit creates a job for the message, sends it to the storage service
+// and answers 202 with the job location without waiting for the reply
+func (h *StoresHandler) submit(c *gin.Context, message contract.Request) {
+	job, err := h.jobService.CreateJob(message.UserLogin, message.Action)
 	if err != nil {
-		h.logger.With(
+		errInf := h.errorMapper.MapError(err)
+
+		c.JSON(errInf.StatusCode, response.BuildJSONResponse("Error", errInf.Message))
+		return
+	}
+
+	if err = h.storageProvider.Send(c.Request.Context(), message, job.ID); err != nil {
+		requestid.Logger(c.Request.Context(), h.logger).With(
 			zap.String("place", "Handler"),
+			zap.String("action", message.Action),
 			zap.Error(err),
 		).Error("Failed to publish a message")
+
+		if err = h.jobService.CompleteJob(job.ID, false, nil, http.StatusInternalServerError, messageForError); err != nil {
+			h.logger.With(zap.Error(err)).Error("Failed to complete job")
+		}
+
 		c.JSON(http.StatusInternalServerError, response.BuildJSONResponse("Error", messageForError))
 		return
 	}
-	c.JSON(http.StatusOK, response.BuildJSONResponse("Success", messageForSuccess))
-}
-
-func (h *StoresHandler) HandleResponse(c *gin.Context) {
-	var payload interface{}
 
-	h.logger.Info("Trying to extract payload from response from storage service")
+	c.Header("Location", "/storage/jobs/"+job.ID)
+	c.JSON(http.StatusAccepted, response.BuildJSONResponse("Accepted", Job{
+		ID:        job.ID,
+		Action:    job.Action,
+		Status:    string(job.Status),
+		CreatedAt: job.CreatedAt,
+		UpdatedAt: job.UpdatedAt,
+	}))
+}
 
-	if err := c.ShouldBindJSON(&payload); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+// request sends the message to the storage service and writes its reply
+// to the client with the given status code on success
+func (h *StoresHandler) request(c *gin.Context, message contract.Request, successStatus int) {
+	reply, ok := h.call(c, message)
+	if !ok {
 		return
 	}
 
-	fmt.Printf("Received payload: %+v\n", payload)
-
-	c.JSON(http.StatusOK, payload)
+	h.writeReply(c, reply, successStatus)
 }
 
-func (h *StoresHandler) sendMessage(message []byte) error {
-	err := h.rabbitMQChannel.Publish(
-		"",
-		h.rabbitMQQueue,
-		false,
-		false,
-		amqp.Publishing{
-			ContentType: "application/json",
-			Body:        message,
-		},
-	)
+// call sends the message to the storage service and waits for the reply.
+// Failures are written to the client and ok is false
+func (h *StoresHandler) call(c *gin.Context, message contract.Request) (*contract.Reply, bool) {
+	reply, err := h.storageProvider.Request(c.Request.Context(), message)
 	if err != nil {
-		return err
+		requestid.Logger(c.Request.Context(), h.logger).With(
+			zap.String("place", "Handler"),
+			zap.String("action", message.Action),
+			zap.Error(err),
+		).Error("Storage service request failed")
+
+		errInf := h.errorMapper.MapError(err)
+
+		if reply != nil && len(reply.Details) > 0 {
+			c.JSON(errInf.StatusCode, response.BuildJSONResponse(errInf.Message, reply.Details))
+			return nil, false
+		}
+
+		c.JSON(errInf.StatusCode, response.BuildJSONResponse("Error", errInf.Message))
+		return nil, false
 	}
 
-	return nil
+	return reply, true
 }
 
-func buildMessage(data interface{}, action, login, storeId, versionId string) []byte {
-	message := map[string]interface{}{
-		"storeId":   storeId,
-		"versionId": versionId,
-		"data":      data,
-		"action":    action,
-		"userLogin": login,
+// writeReply writes a successful reply, its data or just the message if there is none
+func (h *StoresHandler) writeReply(c *gin.Context, reply *contract.Reply, successStatus int) {
+	if len(reply.Data) == 0 {
+		c.JSON(successStatus, response.BuildJSONResponse("Success", reply.Message))
+		return
 	}
 
-	body, err := json.Marshal(message)
+	c.JSON(successStatus, response.BuildJSONResponse("Success", reply.Data))
+}
+
+// ifMatch reads the optional If-Match header into the store revisions it accepts, none without it or for "*".
+// any is true for "*", which accepts any revision of a live store.
+// It answers 400 for a malformed header and 412 when none of the entity tags can match, returning false
+func (h *StoresHandler) ifMatch(c *gin.Context) (revisions []int64, any bool, ok bool) {
+	value := strings.Join(c.Request.Header.Values(ifMatchHeader), ",")
+	if strings.TrimSpace(value) == "" {
+		return nil, false, true
+	}
+
+	revisions, any, err := parseIfMatch(value)
 	if err != nil {
-		return nil
+		c.JSON(http.StatusBadRequest, response.BuildJSONResponse("Error", err.Error()))
+		return nil, false, false
+	}
+	if !any && len(revisions) == 0 {
+		errInf := h.errorMapper.MapError(provider.ErrPrecondition)
+		c.JSON(errInf.StatusCode, response.BuildJSONResponse("Error", errInf.Message))
+		return nil, false, false
 	}
 
-	return body
+	return revisions, any, true
 }
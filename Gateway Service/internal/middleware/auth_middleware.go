@@ -2,10 +2,12 @@ package middleware
 
 import (
 	"GatewayService/internal/handler/response"
+	"GatewayService/internal/requestid"
 	"errors"
 	"fmt"
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt/v4"
+	"github.com/google/uuid"
 	"net/http"
 	"strings"
 )
@@ -57,6 +59,21 @@ func (m *Middleware) AccessTokenValidation() gin.HandlerFunc {
 	}
 }
 
+// RequestID takes the request id from the X-Request-ID header or generates a new one when the header
+// is missing or not a valid id, stores it in the request context and echoes it in the response
+func (m *Middleware) RequestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		requestID := c.GetHeader(requestid.HTTPHeader)
+		if !requestid.IsValid(requestID) {
+			requestID = uuid.NewString()
+		}
+
+		c.Request = c.Request.WithContext(requestid.NewContext(c.Request.Context(), requestID))
+		c.Header(requestid.HTTPHeader, requestID)
+		c.Next()
+	}
+}
+
 func ExtractTokenFromHeader(c *gin.Context) (string, error) {
 	rawAccessToken := c.GetHeader(Header)
 	if rawAccessToken == "" {
@@ -0,0 +1,32 @@
+package migration
+
+import (
+	"embed"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/pressly/goose/v3"
+)
+
+//go:embed migrations/*.sql
+var embedMigrations embed.FS
+
+type Migratory struct {
+}
+
+func NewMigration() *Migratory {
+	return &Migratory{}
+}
+
+func (m *Migratory) Migrate(db *sqlx.DB) error {
+	goose.SetBaseFS(embedMigrations)
+
+	if err := goose.SetDialect("postgres"); err != nil {
+		return err
+	}
+
+	if err := goose.Up(db.DB, "migrations"); err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,20 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS jobs (
+job_id VARCHAR(64) PRIMARY KEY,
+login VARCHAR(255) NOT NULL,
+action VARCHAR(64) NOT NULL,
+status VARCHAR(16) NOT NULL,
+result JSONB,
+error TEXT NOT NULL DEFAULT '',
+created_at TIMESTAMPTZ NOT NULL,
+updated_at TIMESTAMPTZ NOT NULL
+);
+-- expired jobs are found by their last update
+CREATE INDEX IF NOT EXISTS jobs_updated_at_idx ON jobs (updated_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS jobs;
+-- +goose StatementEnd
@@ -0,0 +1,236 @@
+package provider
+
+import (
+	"Contract"
+	"Contract/rabbit"
+	"GatewayService/internal/requestid"
+	"context"
+	"errors"
+	"github.com/google/uuid"
+	"github.com/streadway/amqp"
+	"go.uber.org/zap"
+	"sync"
+	"time"
+)
+
+var (
+	ErrReplyTimeout     = errors.New("storage service did not reply in time")
+	ErrStoreNotFound    = errors.New("store not found")
+	ErrVersionNotFound  = errors.New("store version not found")
+	ErrPermissionDenied = errors.New("user is not a store creator")
+	ErrBadRequest       = errors.New("storage service rejected the request")
+	ErrStorageFailure   = errors.New("storage service failed to process the request")
+	ErrUnknownAction    = errors.New("unknown storage action")
+	ErrIdempotencyKey   = errors.New("idempotency key was already used for another request")
+	ErrOnlyVersion      = errors.New("the only version of a store can't be deleted")
+	ErrPrecondition     = errors.New("the store has changed")
+)
+
+// replyErrors maps error codes sent by the storage service to provider errors
+var replyErrors = map[string]error{
+	contract.CodeStoreNotFound:       ErrStoreNotFound,
+	contract.CodeVersionNotFound:     ErrVersionNotFound,
+	contract.CodePermissionDenied:    ErrPermissionDenied,
+	contract.CodeBadRequest:          ErrBadRequest,
+	contract.CodeIdempotencyConflict: ErrIdempotencyKey,
+	contract.CodeOnlyVersion:         ErrOnlyVersion,
+	contract.CodePreconditionFailed:  ErrPrecondition,
+	contract.CodeUnknownAction:       ErrUnknownAction,
+	contract.CodeUnsupportedSchema:   ErrBadRequest,
+}
+
+// ReplyError returns the provider error matching the reply code, nil for successful replies
+func ReplyError(reply *contract.Reply) error {
+	if reply.Status == contract.StatusSuccess {
+		return nil
+	}
+
+	if err, ok := replyErrors[reply.Code]; ok {
+		return err
+	}
+
+	return ErrStorageFailure
+}
+
+// Publisher sends messages to RabbitMQ, it survives reconnects of the underlying connection
+type Publisher interface {
+	Publish(exchange, key string, mandatory, immediate bool, msg amqp.Publishing) error
+}
+
+// AsyncReplyHandler is called for replies to messages sent with Send
+type AsyncReplyHandler func(correlationID string, reply contract.Reply)
+
+// StorageProvider sends messages to the storage service and waits for the matching reply
+// on an exclusive reply queue. Replies are matched to requests by correlation id.
+type StorageProvider struct {
+	publisher    Publisher
+	exchange     string
+	deliveryMode uint8
+	contentType  string
+	timeout      time.Duration
+	logger       *zap.Logger
+
+	mu           sync.Mutex
+	replyQueue   string
+	pending      map[string]chan contract.Reply
+	asyncHandler AsyncReplyHandler
+}
+
+// NewStorageProvider creates a provider that encodes requests in contentType, one of the contract content types
+func NewStorageProvider(publisher Publisher, topology *rabbit.Topology, contentType string, timeout time.Duration, logger *zap.Logger) *StorageProvider {
+	return &StorageProvider{
+		publisher:    publisher,
+		exchange:     topology.Exchange.Name,
+		deliveryMode: rabbit.DeliveryMode(topology),
+		contentType:  contentType,
+		timeout:      timeout,
+		logger:       logger,
+		pending:      make(map[string]chan contract.Reply),
+	}
+}
+
+// Setup declares the exclusive reply queue and starts consuming it. The queue is gone
+// together with the connection, so it runs again on every reconnect
+func (p *StorageProvider) Setup(_ *amqp.Connection, channel *amqp.Channel) error {
+	replyQueue, err := channel.QueueDeclare(
+		"",    // name
+		false, // durable
+		true,  // delete when unused
+		true,  // exclusive
+		false, // no-wait
+		nil,   // arguments
+	)
+	if err != nil {
+		return err
+	}
+
+	replies, err := channel.Consume(
+		replyQueue.Name, // queue
+		"",              // consumer
+		true,            // auto-ack
+		true,            // exclusive
+		false,           // no-local
+		false,           // no-wait
+		nil,             // args
+	)
+	if err != nil {
+		return err
+	}
+
+	p.mu.Lock()
+	p.replyQueue = replyQueue.Name
+	p.mu.Unlock()
+
+	go p.handleReplies(replies)
+
+	return nil
+}
+
+// HandleAsyncReplies registers the handler for replies nobody is waiting for
+func (p *StorageProvider) HandleAsyncReplies(handler AsyncReplyHandler) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.asyncHandler = handler
+}
+
+// Request publishes the message and blocks until the storage service replies,
+// the timeout expires or the context is cancelled
+func (p *StorageProvider) Request(ctx context.Context, request contract.Request) (*contract.Reply, error) {
+	correlationID := uuid.NewString()
+	replyCh := make(chan contract.Reply, 1)
+
+	p.mu.Lock()
+	p.pending[correlationID] = replyCh
+	p.mu.Unlock()
+
+	defer func() {
+		p.mu.Lock()
+		delete(p.pending, correlationID)
+		p.mu.Unlock()
+	}()
+
+	if err := p.Send(ctx, request, correlationID); err != nil {
+		return nil, err
+	}
+
+	timer := time.NewTimer(p.timeout)
+	defer timer.Stop()
+
+	select {
+	case reply := <-replyCh:
+		return &reply, ReplyError(&reply)
+	case <-timer.C:
+		return nil, ErrReplyTimeout
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// Send publishes the message without waiting, the reply is passed to the async reply handler.
+// The request id from ctx is sent in the message headers
+func (p *StorageProvider) Send(ctx context.Context, request contract.Request, correlationID string) error {
+	routingKey, ok := contract.RoutingKey(request.Action)
+	if !ok {
+		return ErrUnknownAction
+	}
+
+	body, err := contract.EncodeRequest(request, p.contentType)
+	if err != nil {
+		return err
+	}
+
+	p.mu.Lock()
+	replyQueue := p.replyQueue
+	p.mu.Unlock()
+
+	return p.publisher.Publish(
+		p.exchange,
+		routingKey,
+		false,
+		false,
+		amqp.Publishing{
+			ContentType:   p.contentType,
+			DeliveryMode:  p.deliveryMode,
+			CorrelationId: correlationID,
+			ReplyTo:       replyQueue,
+			Headers:       amqp.Table{requestid.AMQPHeader: requestid.FromContext(ctx)},
+			Body:          body,
+		},
+	)
+}
+
+func (p *StorageProvider) handleReplies(replies <-chan amqp.Delivery) {
+	for d := range replies {
+		reply, err := contract.DecodeReply(d.Body, d.ContentType)
+		if err != nil {
+			p.logger.With(
+				zap.String("place", "StorageProvider"),
+				zap.Error(err),
+			).Error("Failed to decode reply from storage service")
+			continue
+		}
+
+		p.mu.Lock()
+		replyCh, ok := p.pending[d.CorrelationId]
+		asyncHandler := p.asyncHandler
+		p.mu.Unlock()
+
+		if ok {
+			replyCh <- *reply
+			continue
+		}
+
+		if asyncHandler == nil {
+			p.logger.With(
+				zap.String("correlationId", d.CorrelationId),
+				zap.String("requestId", reply.RequestID),
+			).Warn("Got reply for unknown or expired request")
+			continue
+		}
+
+		asyncHandler(d.CorrelationId, *reply)
+	}
+
+	p.logger.Warn("Reply consumer stopped")
+}
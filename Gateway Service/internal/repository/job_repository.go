@@ -0,0 +1,162 @@
+package repository
+
+import (
+	"GatewayService/internal/config"
+	"GatewayService/internal/service"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/jmoiron/sqlx"
+	_ "github.com/lib/pq"
+	"go.uber.org/zap"
+	"time"
+)
+
+func ConnectToPostgresDB(cfg *config.DB) (*sqlx.DB, error) {
+	connStr := fmt.Sprintf(
+		"postgres://%s:%s@%s:%s/%s?sslmode=disable",
+		cfg.Username,
+		cfg.Password,
+		cfg.Host,
+		cfg.Port,
+		cfg.DBName,
+	)
+	db, err := sqlx.Open("postgres", connStr)
+	if err != nil {
+		return nil, err
+	}
+
+	err = db.Ping()
+	if err != nil {
+		return nil, err
+	}
+
+	return db, nil
+}
+
+// PostgresJobRepository keeps jobs in Postgres, so they survive a gateway restart and can be polled
+// through any gateway instance. Jobs are evicted once they haven't been updated for the TTL, pending ones too:
+// their reply is lost by then. A zero TTL keeps jobs forever
+type PostgresJobRepository struct {
+	db     *sqlx.DB
+	cfg    *config.JobConfig
+	logger *zap.Logger
+}
+
+func NewPostgresJobRepository(db *sqlx.DB, cfg *config.JobConfig, logger *zap.Logger) *PostgresJobRepository {
+	repo := &PostgresJobRepository{
+		db:     db,
+		cfg:    cfg,
+		logger: logger,
+	}
+	return repo
+}
+
+// jobRow is a row of the jobs table
+type jobRow struct {
+	ID          string    `db:"job_id"`
+	Login       string    `db:"login"`
+	Action      string    `db:"action"`
+	Status      string    `db:"status"`
+	Result      []byte    `db:"result"`
+	Error       string    `db:"error"`
+	ErrorStatus int       `db:"error_status"`
+	CreatedAt   time.Time `db:"created_at"`
+	UpdatedAt   time.Time `db:"updated_at"`
+}
+
+// SaveJob inserts the job or overwrites the saved one with the same id
+func (r *PostgresJobRepository) SaveJob(job service.Job) error {
+	var result []byte
+	if len(job.Result) > 0 {
+		result = job.Result
+	}
+
+	_, err := r.db.Exec(`
+        INSERT INTO jobs (job_id, login, action, status, result, error, error_status, created_at, updated_at)
+        VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+        ON CONFLICT (job_id) DO UPDATE SET
+            status = EXCLUDED.status,
+            result = EXCLUDED.result,
+            error = EXCLUDED.error,
+            error_status = EXCLUDED.error_status,
+            updated_at = EXCLUDED.updated_at
+    `, job.ID, job.Login, job.Action, string(job.Status), result, job.Error, job.ErrorStatus, job.CreatedAt, job.UpdatedAt)
+
+	return err
+}
+
+// GetJobByID doesn't return expired jobs, even if they haven't been evicted yet
+func (r *PostgresJobRepository) GetJobByID(id string) (*service.Job, error) {
+	var row jobRow
+	err := r.db.Get(&row, `
+        SELECT job_id, login, action, status, result, error, error_status, created_at, updated_at
+        FROM jobs
+        WHERE job_id = $1
+    `, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, errors.New("job not found")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if r.expired(row.UpdatedAt, time.Now()) {
+		return nil, errors.New("job not found")
+	}
+
+	return &service.Job{
+		ID:          row.ID,
+		Login:       row.Login,
+		Action:      row.Action,
+		Status:      service.JobStatus(row.Status),
+		Result:      row.Result,
+		Error:       row.Error,
+		ErrorStatus: row.ErrorStatus,
+		CreatedAt:   row.CreatedAt,
+		UpdatedAt:   row.UpdatedAt,
+	}, nil
+}
+
+// Run evicts expired jobs every interval until ctx is cancelled
+func (r *PostgresJobRepository) Run(ctx context.Context) {
+	if r.cfg.TTL <= 0 || r.cfg.EvictInterval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(r.cfg.EvictInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			if _, err := r.EvictExpired(ctx, now); err != nil {
+				r.logger.With(
+					zap.String("place", "JobRepository"),
+					zap.Error(err),
+				).Error("Failed to evict expired jobs")
+			}
+		}
+	}
+}
+
+// EvictExpired removes the jobs that expired by now and returns how many were removed
+func (r *PostgresJobRepository) EvictExpired(ctx context.Context, now time.Time) (int64, error) {
+	if r.cfg.TTL <= 0 {
+		return 0, nil
+	}
+
+	res, err := r.db.ExecContext(ctx, "DELETE FROM jobs WHERE updated_at < $1", now.Add(-r.cfg.TTL))
+	if err != nil {
+		return 0, err
+	}
+
+	return res.RowsAffected()
+}
+
+func (r *PostgresJobRepository) expired(updatedAt, now time.Time) bool {
+	return r.cfg.TTL > 0 && now.Sub(updatedAt) > r.cfg.TTL
+}
@@ -0,0 +1,111 @@
+package repository
+
+import (
+	"GatewayService/internal/config"
+	"GatewayService/internal/migration"
+	"GatewayService/internal/service"
+	"context"
+	"encoding/json"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"go.uber.org/zap"
+	"os"
+	"testing"
+	"time"
+)
+
+// testDatabaseVariable holds the URL of a scratch Postgres database, the tests are skipped without it
+const testDatabaseVariable = "GATEWAY_TEST_DATABASE_URL"
+
+func newTestJobRepository(t *testing.T, cfg *config.JobConfig) *PostgresJobRepository {
+	url := os.Getenv(testDatabaseVariable)
+	if url == "" {
+		t.Skip(testDatabaseVariable + " is not set")
+	}
+
+	db, err := sqlx.Connect("postgres", url)
+	if err != nil {
+		t.Fatalf("connect: %v", err)
+	}
+	t.Cleanup(func() { _ = db.Close() })
+
+	if err = migration.NewMigration().Migrate(db); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+
+	return NewPostgresJobRepository(db, cfg, zap.NewNop())
+}
+
+func TestPostgresJobRepositorySavesJobs(t *testing.T) {
+	repo := newTestJobRepository(t, &config.JobConfig{TTL: time.Hour})
+
+	created := time.Now().UTC().Truncate(time.Microsecond)
+	job := service.Job{ID: uuid.NewString(), Login: "user1", Action: "create_store", Status: service.JobPending, CreatedAt: created, UpdatedAt: created}
+	if err := repo.SaveJob(job); err != nil {
+		t.Fatalf("save pending job: %v", err)
+	}
+
+	job.Status = service.JobFailed
+	job.Error = "The store has changed since the ETag was issued, get it again"
+	job.ErrorStatus = 412
+	job.UpdatedAt = created.Add(time.Second)
+	if err := repo.SaveJob(job); err != nil {
+		t.Fatalf("save failed job: %v", err)
+	}
+
+	saved, err := repo.GetJobByID(job.ID)
+	if err != nil {
+		t.Fatalf("get job: %v", err)
+	}
+	if saved.Status != service.JobFailed || saved.ErrorStatus != 412 || saved.Error != job.Error || saved.Result != nil {
+		t.Errorf("got %+v, want %+v", saved, job)
+	}
+	if !saved.CreatedAt.Equal(created) || !saved.UpdatedAt.Equal(job.UpdatedAt) {
+		t.Errorf("times %v and %v, want %v and %v", saved.CreatedAt, saved.UpdatedAt, created, job.UpdatedAt)
+	}
+
+	job.Status = service.JobSucceeded
+	job.Result = json.RawMessage(`{"storeId":1}`)
+	if err = repo.SaveJob(job); err != nil {
+		t.Fatalf("save succeeded job: %v", err)
+	}
+	if saved, err = repo.GetJobByID(job.ID); err != nil || string(saved.Result) != `{"storeId": 1}` {
+		t.Errorf("result %s with error %v", saved.Result, err)
+	}
+}
+
+func TestPostgresJobRepositoryEvictsExpiredJobs(t *testing.T) {
+	repo := newTestJobRepository(t, &config.JobConfig{TTL: time.Hour, EvictInterval: time.Minute})
+
+	now := time.Now()
+	old := service.Job{ID: uuid.NewString(), Status: service.JobSucceeded, UpdatedAt: now.Add(-2 * time.Hour)}
+	fresh := service.Job{ID: uuid.NewString(), Status: service.JobPending, UpdatedAt: now}
+	_ = repo.SaveJob(old)
+	_ = repo.SaveJob(fresh)
+
+	if _, err := repo.GetJobByID(old.ID); err == nil {
+		t.Error("expired job is returned before it is evicted")
+	}
+
+	if evicted, err := repo.EvictExpired(context.Background(), now); err != nil || evicted < 1 {
+		t.Errorf("evicted %d jobs with error %v, want at least 1", evicted, err)
+	}
+
+	if _, err := repo.GetJobByID(fresh.ID); err != nil {
+		t.Errorf("fresh job: %v", err)
+	}
+}
+
+func TestPostgresJobRepositoryWithoutTTL(t *testing.T) {
+	repo := newTestJobRepository(t, &config.JobConfig{})
+
+	old := service.Job{ID: uuid.NewString(), UpdatedAt: time.Now().Add(-24 * time.Hour)}
+	_ = repo.SaveJob(old)
+
+	if evicted, _ := repo.EvictExpired(context.Background(), time.Now()); evicted != 0 {
+		t.Errorf("evicted %d jobs without a TTL", evicted)
+	}
+	if _, err := repo.GetJobByID(old.ID); err != nil {
+		t.Errorf("job without a TTL: %v", err)
+	}
+}
@@ -0,0 +1,53 @@
+package requestid
+
+import (
+	"context"
+	"go.uber.org/zap"
+)
+
+const (
+	// HTTPHeader is read from incoming requests and echoed in responses
+	HTTPHeader = "X-Request-ID"
+	// AMQPHeader carries the request id to the storage service
+	AMQPHeader = "x-request-id"
+
+	// MaxLength bounds the request ids accepted from clients
+	MaxLength = 64
+)
+
+type contextKey struct{}
+
+func NewContext(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, contextKey{}, requestID)
+}
+
+func FromContext(ctx context.Context) string {
+	requestID, _ := ctx.Value(contextKey{}).(string)
+	return requestID
+}
+
+// IsValid tells whether a request id sent by a client can be used as is: up to MaxLength letters,
+// digits, '-', '_' and '.', so it is safe to log and to pass on in message headers
+func IsValid(requestID string) bool {
+	if requestID == "" || len(requestID) > MaxLength {
+		return false
+	}
+
+	for i := 0; i < len(requestID); i++ {
+		c := requestID[i]
+		if !('a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9' || c == '-' || c == '_' || c == '.') {
+			return false
+		}
+	}
+	return true
+}
+
+// Logger returns the logger annotated with the request id stored in ctx
+func Logger(ctx context.Context, logger *zap.Logger) *zap.Logger {
+	requestID := FromContext(ctx)
+	if requestID == "" {
+		return logger
+	}
+
+	return logger.With(zap.String("requestId", requestID))
+}
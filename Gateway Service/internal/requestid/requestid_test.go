@@ -0,0 +1,29 @@
+package requestid
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestIsValid(t *testing.T) {
+	tests := []struct {
+		requestID string
+		valid     bool
+	}{
+		{"", false},
+		{"3f2c9a1e-7b4d-4c1a-9e2f-0a1b2c3d4e5f", true},
+		{"client_42.retry-1", true},
+		{strings.Repeat("a", MaxLength), true},
+		{strings.Repeat("a", MaxLength+1), false},
+		{"with space", false},
+		{"line\nbreak", false},
+		{"quote\"", false},
+		{"naïve", false},
+	}
+
+	for _, tt := range tests {
+		if got := IsValid(tt.requestID); got != tt.valid {
+			t.Errorf("IsValid(%q) = %v, want %v", tt.requestID, got, tt.valid)
+		}
+	}
+}
@@ -0,0 +1,118 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"time"
+)
+
+type JobRepository interface {
+	SaveJob(job Job) error
+	GetJobByID(id string) (*Job, error)
+}
+
+type JobStatus string
+
+const (
+	JobPending   JobStatus = "pending"
+	JobSucceeded JobStatus = "succeeded"
+	JobFailed    JobStatus = "failed"
+)
+
+// Job is an asynchronous request, Result is set for succeeded jobs, ErrorStatus and Error for failed ones
+type Job struct {
+	ID          string
+	Login       string
+	Action      string
+	Status      JobStatus
+	Result      json.RawMessage
+	Error       string
+	ErrorStatus int
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+var (
+	ErrJobNotFound = errors.New("job not found")
+)
+
+type JobService struct {
+	logger     *zap.Logger
+	repository JobRepository
+}
+
+func NewJobService(logger *zap.Logger, repository JobRepository) *JobService {
+	return &JobService{
+		logger:     logger,
+		repository: repository,
+	}
+}
+
+func (s *JobService) CreateJob(login, action string) (*Job, error) {
+	now := time.Now()
+
+	job := Job{
+		ID:        uuid.NewString(),
+		Login:     login,
+		Action:    action,
+		Status:    JobPending,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	if err := s.repository.SaveJob(job); err != nil {
+		s.logger.With(
+			zap.String("place", "JobService"),
+			zap.Error(err),
+		).Error("Failed to save job")
+		return nil, err
+	}
+
+	return &job, nil
+}
+
+// CompleteJob moves the job out of pending state. Result is kept for succeeded jobs,
+// errorStatus and errorMessage for failed ones
+func (s *JobService) CompleteJob(id string, succeeded bool, result json.RawMessage, errorStatus int, errorMessage string) error {
+	job, err := s.repository.GetJobByID(id)
+	if err != nil {
+		return ErrJobNotFound
+	}
+
+	if succeeded {
+		job.Status = JobSucceeded
+		job.Result = result
+	} else {
+		job.Status = JobFailed
+		job.ErrorStatus = errorStatus
+		job.Error = errorMessage
+	}
+	job.UpdatedAt = time.Now()
+
+	if err = s.repository.SaveJob(*job); err != nil {
+		s.logger.With(
+			zap.String("place", "JobService"),
+			zap.String("jobId", id),
+			zap.Error(err),
+		).Error("Failed to save job")
+		return err
+	}
+
+	return nil
+}
+
+// GetJob returns the job only to the user who created it
+func (s *JobService) GetJob(id, login string) (*Job, error) {
+	job, err := s.repository.GetJobByID(id)
+	if err != nil {
+		return nil, ErrJobNotFound
+	}
+
+	if job.Login != login {
+		return nil, ErrJobNotFound
+	}
+
+	return job, nil
+}
@@ -1,17 +1,26 @@
 package main
 
 import (
+	"Contract/rabbit"
+	"StorageService/internal/admin"
+	"StorageService/internal/broker"
 	"StorageService/internal/config"
 	"StorageService/internal/handler"
 	"StorageService/internal/migration"
+	"StorageService/internal/outbox"
+	"StorageService/internal/purge"
 	"StorageService/internal/repository/postgres"
 	"StorageService/internal/service"
+	"context"
 	"fmt"
 	"github.com/jmoiron/sqlx"
 	"github.com/streadway/amqp"
 	"go.uber.org/zap"
 	"log"
+	"net/http"
 	"os"
+	"os/signal"
+	"syscall"
 	"time"
 )
 
@@ -44,88 +53,160 @@ func main() {
 	}
 	defer repository.Close()
 
-	rabbitConnection, err := initRabbitMQConnection(cfg)
+	mqConfig, err := cfg.GetRabbitMQConfig()
 	if err != nil {
 		logger.With(
 			zap.String("place", "main"),
 			zap.Error(err),
-		).Panic("Failed to establish RabbitMQ rabbitConnection")
+		).Panic("Failed to read RabbitMQ config")
 	}
 
-	channel, err := initRabbitChannel(rabbitConnection)
+	topology, err := cfg.GetRabbitTopologyConfig()
 	if err != nil {
 		logger.With(
 			zap.String("place", "main"),
 			zap.Error(err),
-		).Panic("Failed to init RabbitMQ channel")
+		).Panic("Failed to read RabbitMQ topology config")
 	}
 
-	queue, err := declareRabbitQueue(channel)
-	if err != nil {
-		logger.With(
-			zap.String("place", "main"),
-			zap.Error(err),
-		).Panic("Failed to init RabbitMQ queue")
-	}
+	connectionManager := rabbit.NewConnectionManager(cfg.GetAMQPConnectionURL(mqConfig), mqConfig.MinBackoff, mqConfig.MaxBackoff, logger)
+	defer connectionManager.Close()
 
-	gatewayUrl := cfg.GetGatewayServerUrl()
 	storeService := service.NewStoreService(logger, repository)
-	messageHandler := handler.NewMessageHandler(storeService, gatewayUrl, logger)
-
-	msgs, err := channel.Consume(
-		queue.Name, // queue
-		"",         // consumer
-		true,       // auto-ack
-		false,      // exclusive
-		false,      // no-local
-		false,      // no-wait
-		nil,        // args
-	)
+	messageHandler := handler.NewMessageHandler(storeService, connectionManager, logger)
+	deadLetterQueue := broker.NewDeadLetterQueue(topology.DeadLetterQueue)
+	consumerGroup := broker.NewConsumerGroup()
+	outboxRelay := outbox.NewRelay(repository, cfg.GetOutboxConfig(), logger)
+	purger := purge.NewPurger(repository, cfg.GetPurgeConfig(), logger)
+
+	// setup runs again on every reconnect
+	connectionManager.OnConnect(func(_ *amqp.Connection, channel *amqp.Channel) error {
+		return rabbit.DeclareTopology(channel, topology)
+	})
+	connectionManager.OnConnect(func(conn *amqp.Connection, _ *amqp.Channel) error {
+		return consumerGroup.Replace(func() ([]*broker.Consumer, error) {
+			// every queue gets its own channel and consumer, so reads and writes don't wait for each other
+			consumers := make([]*broker.Consumer, 0, len(topology.Queues))
+			for _, queue := range topology.Queues {
+				consumer, err := startConsumer(conn, queue.Name, messageHandler, mqConfig, logger)
+				if err != nil {
+					return consumers, fmt.Errorf("failed to register a consumer for %s: %w", queue.Name, err)
+				}
+				consumers = append(consumers, consumer)
+			}
+			return consumers, nil
+		})
+	})
+	connectionManager.OnConnect(deadLetterQueue.Setup)
+	connectionManager.OnConnect(outboxRelay.Setup)
 
-	if err != nil {
+	if err = connectionManager.Start(); err != nil {
 		logger.With(
 			zap.String("place", "main"),
 			zap.Error(err),
-		).Panic("Failed to register a consumer")
+		).Panic("Failed to establish RabbitMQ connection")
 	}
 
-	var forever chan struct{}
+	backgroundCtx, stopBackground := context.WithCancel(context.Background())
+	relayDone := make(chan struct{})
+	go func() {
+		defer close(relayDone)
+		outboxRelay.Run(backgroundCtx)
+	}()
 
+	purgeDone := make(chan struct{})
 	go func() {
-		for d := range msgs {
-			log.Printf("Received a message: %s", d.Body)
-			messageHandler.HandleMessage(d)
+		defer close(purgeDone)
+		purger.Run(backgroundCtx)
+	}()
+
+	deadLetterHandler := admin.NewDeadLetterHandler(deadLetterQueue, logger)
+	readinessHandler := admin.NewReadinessHandler(connectionManager)
+
+	adminCfg := cfg.GetAdminServerConfig()
+	adminServer := &http.Server{
+		Addr:    adminCfg.Host + ":" + adminCfg.Port,
+		Handler: admin.NewRouter(deadLetterHandler, readinessHandler, adminCfg.Token),
+	}
+
+	go func() {
+		logger.Info("Admin server is running")
+		if err := adminServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			logger.With(
+				zap.String("place", "main"),
+				zap.Error(err),
+			).Error("Admin server failed during run")
 		}
 	}()
 
 	logger.Info("Waiting for messages")
-	<-forever
-}
 
-func declareRabbitQueue(channel *amqp.Channel) (amqp.Queue, error) {
-	queue, err := channel.QueueDeclare(
-		"CreateQueue", // name
-		false,         // durable
-		false,         // delete when unused
-		false,         // exclusive
-		false,         // no-wait
-		nil,           // arguments
-	)
-	return queue, err
+	shutDown := make(chan os.Signal, 1)
+
+	signal.Notify(shutDown, os.Interrupt, syscall.SIGTERM, syscall.SIGINT)
+
+	s := <-shutDown
+
+	logger.With(
+		zap.String("signal", s.String()),
+	).Info("Shutting down, draining in-flight messages")
+
+	// /ready fails first, so nothing new is routed here while the consumers drain
+	readinessHandler.MarkStopping()
+
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.GetShutdownTimeout())
+	defer cancel()
+
+	// messages still in the workers' buffers go back to the queue, the rest is finished within the deadline.
+	// Shutdown returns only after the workers exited, messages cancelled at the deadline are redelivered
+	if err = consumerGroup.Shutdown(ctx); err != nil {
+		logger.With(
+			zap.String("place", "main"),
+			zap.Error(err),
+		).Error("Consumers didn't finish in time, unacknowledged messages will be redelivered")
+	}
+
+	stopBackground()
+	<-relayDone
+	<-purgeDone
+
+	// the admin server stays up while draining, so readiness can be probed and dead letters inspected.
+	// It gets its own deadline, so a slow admin request doesn't eat into the consumers' time
+	adminCtx, cancelAdmin := context.WithTimeout(context.Background(), cfg.GetAdminShutdownTimeout())
+	defer cancelAdmin()
+
+	if err = adminServer.Shutdown(adminCtx); err != nil {
+		logger.With(
+			zap.String("place", "main"),
+			zap.Error(err),
+		).Error("Failed to shut down admin server")
+	}
+
+	// nothing uses them anymore, deferred calls close the RabbitMQ connection and then the database pool
+	logger.Info("Consumers stopped")
 }
 
-func initRabbitChannel(connection *amqp.Connection) (*amqp.Channel, error) {
+func startConsumer(connection *amqp.Connection, queue string, messageHandler broker.Handler, mqConfig *config.RabbitMQConfig, logger *zap.Logger) (*broker.Consumer, error) {
 	channel, err := connection.Channel()
+	if err != nil {
+		return nil, err
+	}
 
-	return channel, err
-}
+	retry := broker.RetryPolicy{
+		MaxRetries: mqConfig.MaxRetries,
+		MinBackoff: mqConfig.RetryMinBackoff,
+		MaxBackoff: mqConfig.RetryMaxBackoff,
+	}
 
-func initRabbitMQConnection(cfg *config.Configurator) (*amqp.Connection, error) {
-	mqConfig := cfg.GetRabbitMQConfig()
+	consumer := broker.NewConsumer(channel, messageHandler, retry, mqConfig.Workers, mqConfig.Prefetch,
+		logger.With(zap.String("queue", queue)))
 
-	conn, err := amqp.Dial(cfg.GetAMQPConnectionURL(mqConfig))
+	if err = consumer.Start(queue); err != nil {
+		_ = channel.Close()
+		return nil, err
+	}
 
-	return conn, err
+	return consumer, nil
 }
 
 func initLogger() (*zap.Logger, error) {
@@ -162,7 +243,7 @@ func initDB(cfg *config.Configurator, migrator *migration.Migratory, logger *zap
 
 			txOpts := cfg.GetTxOptions()
 
-			repo = postgres.NewPostgresRepository(db, txOpts)
+			repo = postgres.NewPostgresRepository(db, txOpts, logger)
 
 			logger.Info("Migrations done")
 
@@ -0,0 +1,143 @@
+package admin
+
+import (
+	"StorageService/internal/broker"
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"go.uber.org/zap"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+const (
+	defaultLimit = 10
+	// maxLimit bounds how many dead letters one request takes from the queue and holds in memory
+	maxLimit = 100
+)
+
+type DeadLetterQueue interface {
+	Peek(limit int) ([]broker.DeadLetter, error)
+	Requeue(limit int) (int, error)
+}
+
+type DeadLetterHandler struct {
+	queue  DeadLetterQueue
+	logger *zap.Logger
+}
+
+func NewDeadLetterHandler(queue DeadLetterQueue, logger *zap.Logger) *DeadLetterHandler {
+	return &DeadLetterHandler{
+		queue:  queue,
+		logger: logger,
+	}
+}
+
+// Peek handles GET /admin/dlq?limit=N
+func (h *DeadLetterHandler) Peek(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "method not allowed"})
+		return
+	}
+
+	limit, ok := parseLimit(w, r)
+	if !ok {
+		return
+	}
+
+	letters, err := h.queue.Peek(limit)
+	if err != nil {
+		h.logger.With(
+			zap.String("place", "DeadLetterHandler"),
+			zap.Error(err),
+		).Error("Failed to read dead-letter queue")
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to read dead-letter queue"})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, letters)
+}
+
+// Requeue handles POST /admin/dlq/requeue?limit=N
+func (h *DeadLetterHandler) Requeue(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "method not allowed"})
+		return
+	}
+
+	limit, ok := parseLimit(w, r)
+	if !ok {
+		return
+	}
+
+	requeued, err := h.queue.Requeue(limit)
+	if err != nil {
+		h.logger.With(
+			zap.String("place", "DeadLetterHandler"),
+			zap.Int("requeued", requeued),
+			zap.Error(err),
+		).Error("Failed to requeue dead letters")
+		writeJSON(w, http.StatusInternalServerError, map[string]interface{}{
+			"error":    "failed to requeue dead letters",
+			"requeued": requeued,
+		})
+		return
+	}
+
+	h.logger.Info("Dead letters requeued", zap.Int("requeued", requeued))
+
+	writeJSON(w, http.StatusOK, map[string]int{"requeued": requeued})
+}
+
+// NewRouter serves readiness to anyone and the dead-letter endpoints only to requests
+// with the admin token. Without a token the dead-letter endpoints are turned off
+func NewRouter(deadLetterHandler *DeadLetterHandler, readinessHandler *ReadinessHandler, token string) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/ready", readinessHandler.Ready)
+	mux.HandleFunc("/admin/dlq", requireToken(token, deadLetterHandler.Peek))
+	mux.HandleFunc("/admin/dlq/requeue", requireToken(token, deadLetterHandler.Requeue))
+
+	return mux
+}
+
+// requireToken lets through requests with an "Authorization: Bearer <token>" header
+func requireToken(token string, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if token == "" {
+			writeJSON(w, http.StatusForbidden, map[string]string{"error": "admin endpoints are disabled, set admin.token"})
+			return
+		}
+
+		given, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(given), []byte(token)) != 1 {
+			writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "invalid admin token"})
+			return
+		}
+
+		next(w, r)
+	}
+}
+
+// parseLimit reads the optional limit parameter, defaultLimit without it.
+// Limits out of range are answered with 400 and ok is false
+func parseLimit(w http.ResponseWriter, r *http.Request) (limit int, ok bool) {
+	value := r.URL.Query().Get("limit")
+	if value == "" {
+		return defaultLimit, true
+	}
+
+	limit, err := strconv.Atoi(value)
+	if err != nil || limit < 1 || limit > maxLimit {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": fmt.Sprintf("limit must be between 1 and %d", maxLimit)})
+		return 0, false
+	}
+
+	return limit, true
+}
+
+func writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
@@ -0,0 +1,67 @@
+package admin
+
+import (
+	"StorageService/internal/broker"
+	"go.uber.org/zap"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type fakeQueue struct {
+	limit int
+}
+
+func (q *fakeQueue) Peek(limit int) ([]broker.DeadLetter, error) {
+	q.limit = limit
+	return nil, nil
+}
+
+func (q *fakeQueue) Requeue(limit int) (int, error) {
+	q.limit = limit
+	return 0, nil
+}
+
+func TestDeadLetterLimit(t *testing.T) {
+	tests := []struct {
+		query  string
+		status int
+		limit  int
+	}{
+		{"", http.StatusOK, defaultLimit},
+		{"?limit=5", http.StatusOK, 5},
+		{"?limit=100", http.StatusOK, maxLimit},
+		{"?limit=1000000", http.StatusBadRequest, 0},
+		{"?limit=0", http.StatusBadRequest, 0},
+		{"?limit=ten", http.StatusBadRequest, 0},
+	}
+
+	for _, tt := range tests {
+		queue := &fakeQueue{}
+		router := NewRouter(NewDeadLetterHandler(queue, zap.NewNop()), NewReadinessHandler(nil), "secret")
+
+		request := httptest.NewRequest(http.MethodGet, "/admin/dlq"+tt.query, nil)
+		request.Header.Set("Authorization", "Bearer secret")
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, request)
+
+		if recorder.Code != tt.status || queue.limit != tt.limit {
+			t.Errorf("%q: got %d with limit %d, want %d with limit %d", tt.query, recorder.Code, queue.limit, tt.status, tt.limit)
+		}
+	}
+}
+
+func TestDeadLetterEndpointsNeedToken(t *testing.T) {
+	for token, want := range map[string]int{"": http.StatusForbidden, "secret": http.StatusUnauthorized} {
+		router := NewRouter(NewDeadLetterHandler(&fakeQueue{}, zap.NewNop()), NewReadinessHandler(nil), token)
+
+		request := httptest.NewRequest(http.MethodGet, "/admin/dlq", nil)
+		request.Header.Set("Authorization", "Bearer guess")
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, request)
+
+		if recorder.Code != want {
+			t.Errorf("token %q: got %d, want %d", token, recorder.Code, want)
+		}
+	}
+}
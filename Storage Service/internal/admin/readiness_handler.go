@@ -0,0 +1,42 @@
+package admin
+
+import (
+	"net/http"
+	"sync/atomic"
+)
+
+type ReadinessChecker interface {
+	IsReady() bool
+}
+
+type ReadinessHandler struct {
+	broker   ReadinessChecker
+	stopping atomic.Bool
+}
+
+func NewReadinessHandler(broker ReadinessChecker) *ReadinessHandler {
+	return &ReadinessHandler{
+		broker: broker,
+	}
+}
+
+// MarkStopping makes Ready answer 503 from now on, the service is shutting down
+func (h *ReadinessHandler) MarkStopping() {
+	h.stopping.Store(true)
+}
+
+// Ready handles GET /ready, it answers 503 while the RabbitMQ connection is down or being restored
+// and once the service is shutting down
+func (h *ReadinessHandler) Ready(w http.ResponseWriter, _ *http.Request) {
+	if h.stopping.Load() {
+		writeJSON(w, http.StatusServiceUnavailable, map[string]string{"status": "shutting down"})
+		return
+	}
+
+	if !h.broker.IsReady() {
+		writeJSON(w, http.StatusServiceUnavailable, map[string]string{"status": "rabbitmq not connected"})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]string{"status": "ready"})
+}
@@ -0,0 +1,31 @@
+package admin
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type fakeBroker bool
+
+func (b fakeBroker) IsReady() bool {
+	return bool(b)
+}
+
+func TestReadyFailsOnceStopping(t *testing.T) {
+	handler := NewReadinessHandler(fakeBroker(true))
+
+	recorder := httptest.NewRecorder()
+	handler.Ready(recorder, httptest.NewRequest(http.MethodGet, "/ready", nil))
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("status %d before shutdown, want 200", recorder.Code)
+	}
+
+	handler.MarkStopping()
+
+	recorder = httptest.NewRecorder()
+	handler.Ready(recorder, httptest.NewRequest(http.MethodGet, "/ready", nil))
+	if recorder.Code != http.StatusServiceUnavailable {
+		t.Errorf("status %d while shutting down, want 503", recorder.Code)
+	}
+}
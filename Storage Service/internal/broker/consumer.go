@@ -0,0 +1,316 @@
+package broker
+
+import (
+	"context"
+	"errors"
+	"github.com/streadway/amqp"
+	"go.uber.org/zap"
+	"hash/fnv"
+	"sync"
+	"time"
+)
+
+// DeliveryCountHeader is set by quorum queues to the number of earlier deliveries of a message
+const DeliveryCountHeader = "x-delivery-count"
+
+// RetryPolicy says how often a failed message is tried again and how long to wait in between.
+// The wait starts at MinBackoff and doubles up to MaxBackoff
+type RetryPolicy struct {
+	MaxRetries int
+	MinBackoff time.Duration
+	MaxBackoff time.Duration
+}
+
+// ErrMalformedMessage marks messages that can never be processed. They go straight to the dead-letter queue
+var ErrMalformedMessage = errors.New("malformed message")
+
+type Handler interface {
+	// HandleMessage returns nil when the message is processed and may be acknowledged.
+	// ctx is cancelled when the consumer didn't finish in time on shutdown
+	HandleMessage(ctx context.Context, msg amqp.Delivery) error
+	// HandleDeadLetter is called once the message runs out of retries
+	HandleDeadLetter(msg amqp.Delivery, err error)
+	// OrderingKey returns the key of messages that must be processed in order, empty if order doesn't matter
+	OrderingKey(msg amqp.Delivery) string
+}
+
+// Consumer processes deliveries with a pool of workers and acknowledges them manually.
+// Messages with the same ordering key always go to the same worker, so they are processed in order.
+// A failed message is retried by its worker with backoff, later messages of the worker wait for it.
+// Once the retries run out it is rejected to the dead-letter exchange
+type Consumer struct {
+	channel  *amqp.Channel
+	handler  Handler
+	retry    RetryPolicy
+	workers  int
+	prefetch int
+	logger   *zap.Logger
+
+	tag        string
+	stopping   chan struct{}
+	stopOnce   sync.Once
+	done       chan struct{}
+	workCtx    context.Context
+	cancelWork context.CancelFunc
+}
+
+// NewConsumer creates a consumer with the given number of workers on its own channel.
+// Each worker buffers up to prefetch messages
+func NewConsumer(channel *amqp.Channel, handler Handler, retry RetryPolicy, workers, prefetch int, logger *zap.Logger) *Consumer {
+	if workers < 1 {
+		workers = 1
+	}
+
+	workCtx, cancelWork := context.WithCancel(context.Background())
+
+	return &Consumer{
+		channel:    channel,
+		handler:    handler,
+		retry:      retry,
+		workers:    workers,
+		prefetch:   prefetch,
+		logger:     logger,
+		stopping:   make(chan struct{}),
+		done:       make(chan struct{}),
+		workCtx:    workCtx,
+		cancelWork: cancelWork,
+	}
+}
+
+// Start subscribes to the queue and processes its messages in the background
+func (c *Consumer) Start(queue string) error {
+	err := c.channel.Qos(
+		c.prefetch, // prefetch count
+		0,          // prefetch size
+		false,      // global
+	)
+	if err != nil {
+		return err
+	}
+
+	c.tag = queue
+
+	deliveries, err := c.channel.Consume(
+		queue, // queue
+		c.tag, // consumer
+		false, // auto-ack
+		false, // exclusive
+		false, // no-local
+		false, // no-wait
+		nil,   // args
+	)
+	if err != nil {
+		return err
+	}
+
+	go c.consume(deliveries)
+
+	return nil
+}
+
+// Stop cancels the subscription. Messages already being handled are finished,
+// messages still waiting for a worker are returned to the queue
+func (c *Consumer) Stop() {
+	c.stopOnce.Do(func() {
+		close(c.stopping)
+
+		if err := c.channel.Cancel(c.tag, false); err != nil {
+			c.logger.With(zap.Error(err)).Error("Failed to cancel consumer")
+		}
+	})
+}
+
+// Shutdown stops the consumer and waits for its workers until ctx is done. Then messages still being
+// handled are cancelled and returned to the queue. It returns once every worker exited and the channel
+// is closed, so the resources the handler uses can be released
+func (c *Consumer) Shutdown(ctx context.Context) error {
+	c.Stop()
+	defer c.channel.Close()
+
+	select {
+	case <-c.done:
+		return nil
+	case <-ctx.Done():
+		c.cancelWork()
+		<-c.done
+		return ctx.Err()
+	}
+}
+
+// abandon waits for the workers of a consumer whose connection was lost. Nothing can be acknowledged anymore
+// and the broker delivers the messages again, so handling is cancelled right away
+func (c *Consumer) abandon() {
+	c.stopOnce.Do(func() {
+		close(c.stopping)
+	})
+	c.cancelWork()
+	<-c.done
+}
+
+// consume dispatches deliveries to workers and returns once the deliveries channel is closed
+// and every worker finished its messages
+func (c *Consumer) consume(deliveries <-chan amqp.Delivery) {
+	defer close(c.done)
+	defer c.cancelWork()
+
+	workers := make([]chan amqp.Delivery, c.workers)
+
+	var wg sync.WaitGroup
+	for i := range workers {
+		workers[i] = make(chan amqp.Delivery, c.prefetch)
+
+		wg.Add(1)
+		go func(jobs <-chan amqp.Delivery) {
+			defer wg.Done()
+			for d := range jobs {
+				select {
+				case <-c.stopping:
+					c.requeue(d)
+				default:
+					c.process(d)
+				}
+			}
+		}(workers[i])
+	}
+
+	next := 0
+	for d := range deliveries {
+		key := c.handler.OrderingKey(d)
+		if key == "" {
+			workers[next] <- d
+			next = (next + 1) % c.workers
+			continue
+		}
+
+		workers[workerIndex(key, c.workers)] <- d
+	}
+
+	for _, jobs := range workers {
+		close(jobs)
+	}
+	wg.Wait()
+
+	c.logger.Warn("Deliveries channel closed, consumer stopped")
+}
+
+func workerIndex(key string, workers int) int {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(key))
+	return int(h.Sum32() % uint32(workers))
+}
+
+// process handles the message until it succeeds or runs out of retries. It keeps the worker busy
+// while waiting between retries, so later messages with the same ordering key can't overtake it.
+// Earlier deliveries of the message count as retries, so it can't be retried forever across restarts
+func (c *Consumer) process(d amqp.Delivery) {
+	backoff := c.retry.MinBackoff
+
+	for retries := DeliveryCount(d); ; retries++ {
+		err := c.handler.HandleMessage(c.workCtx, d)
+		if err == nil {
+			c.ack(d)
+			return
+		}
+
+		logger := c.logger.With(
+			zap.String("correlationId", d.CorrelationId),
+			zap.Int("retries", retries),
+			zap.Error(err),
+		)
+
+		if errors.Is(err, ErrMalformedMessage) {
+			logger.Error("Malformed message, sending to dead-letter queue")
+			c.reject(d)
+			return
+		}
+
+		if c.workCtx.Err() != nil {
+			logger.Warn("Message was cancelled on shutdown, returning it to the queue")
+			c.requeue(d)
+			return
+		}
+
+		if retries >= c.retry.MaxRetries {
+			logger.Error("Message ran out of retries, sending to dead-letter queue")
+			c.handler.HandleDeadLetter(d, err)
+			c.reject(d)
+			return
+		}
+
+		logger.With(zap.Duration("retryIn", backoff)).Warn("Failed to process message, retrying")
+
+		select {
+		case <-time.After(backoff):
+		case <-c.stopping:
+			// the broker puts the message back in its place, later messages of the worker follow it
+			logger.Warn("Consumer is stopping, returning message to the queue")
+			c.requeue(d)
+			return
+		}
+
+		backoff *= 2
+		if backoff > c.retry.MaxBackoff {
+			backoff = c.retry.MaxBackoff
+		}
+	}
+}
+
+func (c *Consumer) ack(d amqp.Delivery) {
+	if err := d.Ack(false); err != nil {
+		c.logger.With(zap.Error(err)).Error("Failed to ack message")
+	}
+}
+
+// requeue returns the message to its queue without counting it as a retry
+func (c *Consumer) requeue(d amqp.Delivery) {
+	if err := d.Nack(false, true); err != nil {
+		c.logger.With(zap.Error(err)).Error("Failed to nack message")
+	}
+}
+
+// reject drops the message from the queue, the broker routes it to the dead-letter exchange
+func (c *Consumer) reject(d amqp.Delivery) {
+	if err := d.Nack(false, false); err != nil {
+		c.logger.With(zap.Error(err)).Error("Failed to nack message")
+	}
+}
+
+// DeliveryCount returns how often the message was delivered before. Classic queues don't count deliveries,
+// there a redelivered message counts as delivered once
+func DeliveryCount(d amqp.Delivery) int {
+	switch v := d.Headers[DeliveryCountHeader].(type) {
+	case int:
+		return v
+	case int16:
+		return int(v)
+	case int32:
+		return int(v)
+	case int64:
+		return int(v)
+	}
+
+	if d.Redelivered {
+		return 1
+	}
+	return 0
+}
+
+// republished copies the delivery into a new publishing. The copy is a new message to the broker,
+// so its delivery count starts over
+func republished(d amqp.Delivery) amqp.Publishing {
+	headers := amqp.Table{}
+	for k, v := range d.Headers {
+		headers[k] = v
+	}
+	delete(headers, DeliveryCountHeader)
+
+	return amqp.Publishing{
+		Headers:       headers,
+		ContentType:   d.ContentType,
+		DeliveryMode:  d.DeliveryMode,
+		CorrelationId: d.CorrelationId,
+		ReplyTo:       d.ReplyTo,
+		MessageId:     d.MessageId,
+		Body:          d.Body,
+	}
+}
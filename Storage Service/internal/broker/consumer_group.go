@@ -0,0 +1,63 @@
+package broker
+
+import (
+	"context"
+	"sync"
+)
+
+// ConsumerGroup holds the consumers of the current connection, so they can be shut down together
+type ConsumerGroup struct {
+	mu        sync.Mutex
+	consumers []*Consumer
+	closed    bool
+}
+
+func NewConsumerGroup() *ConsumerGroup {
+	return &ConsumerGroup{}
+}
+
+// Replace waits for the consumers of the previous connection to exit and then starts new ones with start.
+// Messages they were handling can't be acknowledged on the closed connection and come back from the broker,
+// so their handling is cancelled right away. Waiting keeps a redelivered message from running next to
+// its first delivery and overtaking it. start returns the consumers it started even when it fails,
+// so the next Replace or Shutdown stops them. Nothing is started once the group was shut down
+func (g *ConsumerGroup) Replace(start func() ([]*Consumer, error)) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	for _, consumer := range g.consumers {
+		consumer.abandon()
+	}
+	g.consumers = nil
+
+	if g.closed {
+		return nil
+	}
+
+	consumers, err := start()
+	g.consumers = consumers
+
+	return err
+}
+
+// Shutdown stops every consumer first and then waits for all of them until ctx is done.
+// It returns once the workers of every consumer exited, messages left are cancelled at the deadline
+func (g *ConsumerGroup) Shutdown(ctx context.Context) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	g.closed = true
+
+	for _, consumer := range g.consumers {
+		consumer.Stop()
+	}
+
+	var err error
+	for _, consumer := range g.consumers {
+		if shutdownErr := consumer.Shutdown(ctx); shutdownErr != nil && err == nil {
+			err = shutdownErr
+		}
+	}
+
+	return err
+}
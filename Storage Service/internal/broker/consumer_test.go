@@ -0,0 +1,198 @@
+package broker
+
+import (
+	"context"
+	"errors"
+	"github.com/streadway/amqp"
+	"go.uber.org/zap"
+	"testing"
+	"time"
+)
+
+// acknowledger records what the consumer did with a delivery
+type acknowledger struct {
+	acked    int
+	requeued int
+	rejected int
+}
+
+func (a *acknowledger) Ack(uint64, bool) error { a.acked++; return nil }
+
+func (a *acknowledger) Nack(_ uint64, _ bool, requeue bool) error {
+	if requeue {
+		a.requeued++
+	} else {
+		a.rejected++
+	}
+	return nil
+}
+
+func (a *acknowledger) Reject(tag uint64, requeue bool) error { return a.Nack(tag, false, requeue) }
+
+// failingHandler fails the first failures calls with err
+type failingHandler struct {
+	failures    int
+	err         error
+	calls       int
+	deadLetters int
+}
+
+func (h *failingHandler) HandleMessage(context.Context, amqp.Delivery) error {
+	h.calls++
+	if h.calls <= h.failures {
+		return h.err
+	}
+	return nil
+}
+
+func (h *failingHandler) HandleDeadLetter(amqp.Delivery, error) { h.deadLetters++ }
+
+func (h *failingHandler) OrderingKey(amqp.Delivery) string { return "" }
+
+func newTestConsumer(handler Handler, maxRetries int) *Consumer {
+	retry := RetryPolicy{MaxRetries: maxRetries, MinBackoff: time.Millisecond, MaxBackoff: 2 * time.Millisecond}
+	return NewConsumer(nil, handler, retry, 1, 1, zap.NewNop())
+}
+
+func TestConsumerRetriesInPlace(t *testing.T) {
+	handler := &failingHandler{failures: 2, err: errors.New("database is down")}
+	ack := &acknowledger{}
+
+	newTestConsumer(handler, 3).process(amqp.Delivery{Acknowledger: ack})
+
+	if handler.calls != 3 || ack.acked != 1 || ack.requeued != 0 || ack.rejected != 0 {
+		t.Errorf("got %d calls, %+v, want 3 calls and one ack", handler.calls, *ack)
+	}
+}
+
+func TestConsumerDeadLettersAfterRetries(t *testing.T) {
+	handler := &failingHandler{failures: 10, err: errors.New("database is down")}
+	ack := &acknowledger{}
+
+	newTestConsumer(handler, 2).process(amqp.Delivery{Acknowledger: ack})
+
+	if handler.calls != 3 || handler.deadLetters != 1 || ack.rejected != 1 || ack.acked != 0 {
+		t.Errorf("got %d calls, %d dead letters, %+v, want 3 calls and one rejection", handler.calls, handler.deadLetters, *ack)
+	}
+}
+
+func TestConsumerCountsEarlierDeliveries(t *testing.T) {
+	tests := []struct {
+		name     string
+		delivery amqp.Delivery
+		calls    int
+	}{
+		{"quorum queue", amqp.Delivery{Headers: amqp.Table{DeliveryCountHeader: int64(2)}}, 1},
+		{"classic queue", amqp.Delivery{Redelivered: true}, 2},
+	}
+
+	for _, tt := range tests {
+		handler := &failingHandler{failures: 10, err: errors.New("database is down")}
+		ack := &acknowledger{}
+		tt.delivery.Acknowledger = ack
+
+		newTestConsumer(handler, 2).process(tt.delivery)
+
+		if handler.calls != tt.calls || handler.deadLetters != 1 || ack.rejected != 1 {
+			t.Errorf("%s: got %d calls, %d dead letters, %+v, want %d calls and one rejection",
+				tt.name, handler.calls, handler.deadLetters, *ack, tt.calls)
+		}
+	}
+}
+
+func TestConsumerRejectsMalformedMessages(t *testing.T) {
+	handler := &failingHandler{failures: 1, err: ErrMalformedMessage}
+	ack := &acknowledger{}
+
+	newTestConsumer(handler, 3).process(amqp.Delivery{Acknowledger: ack})
+
+	if handler.calls != 1 || ack.rejected != 1 || handler.deadLetters != 0 {
+		t.Errorf("got %d calls, %d dead letters, %+v, want one call and one rejection", handler.calls, handler.deadLetters, *ack)
+	}
+}
+
+func TestConsumerRequeuesWhileStopping(t *testing.T) {
+	handler := &failingHandler{failures: 10, err: errors.New("database is down")}
+	ack := &acknowledger{}
+
+	consumer := newTestConsumer(handler, 3)
+	consumer.retry.MinBackoff = time.Hour
+	close(consumer.stopping)
+
+	consumer.process(amqp.Delivery{Acknowledger: ack})
+
+	if handler.calls != 1 || ack.requeued != 1 || handler.deadLetters != 0 {
+		t.Errorf("got %d calls, %d dead letters, %+v, want one call and one requeue", handler.calls, handler.deadLetters, *ack)
+	}
+}
+
+func TestConsumerRequeuesCancelledMessages(t *testing.T) {
+	handler := &failingHandler{failures: 10, err: context.Canceled}
+	ack := &acknowledger{}
+
+	// the shutdown deadline passed, the message must go back to the queue instead of the dead-letter queue
+	consumer := newTestConsumer(handler, 0)
+	consumer.cancelWork()
+	consumer.process(amqp.Delivery{Acknowledger: ack})
+
+	if handler.calls != 1 || handler.deadLetters != 0 || ack.requeued != 1 || ack.rejected != 0 {
+		t.Errorf("got %d calls, %d dead letters, %+v, want one requeue", handler.calls, handler.deadLetters, *ack)
+	}
+}
+
+// blockingHandler handles messages until the consumer cancels them
+type blockingHandler struct {
+	started chan struct{}
+}
+
+func (h *blockingHandler) HandleMessage(ctx context.Context, _ amqp.Delivery) error {
+	close(h.started)
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+func (h *blockingHandler) HandleDeadLetter(amqp.Delivery, error) {}
+
+func (h *blockingHandler) OrderingKey(amqp.Delivery) string { return "" }
+
+func TestConsumerGroupReplaceWaitsForPreviousConsumers(t *testing.T) {
+	handler := &blockingHandler{started: make(chan struct{})}
+	previous := newTestConsumer(handler, 3)
+
+	deliveries := make(chan amqp.Delivery, 1)
+	deliveries <- amqp.Delivery{Acknowledger: &acknowledger{}}
+	go previous.consume(deliveries)
+	<-handler.started
+
+	group := NewConsumerGroup()
+	if err := group.Replace(func() ([]*Consumer, error) { return []*Consumer{previous}, nil }); err != nil {
+		t.Fatal(err)
+	}
+
+	// the connection is gone, so the broker closes the deliveries channel
+	close(deliveries)
+
+	err := group.Replace(func() ([]*Consumer, error) {
+		select {
+		case <-previous.done:
+		default:
+			t.Error("new consumers started while the previous ones were still running")
+		}
+		return nil, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestConsumerGroupStartsNothingAfterShutdown(t *testing.T) {
+	group := NewConsumerGroup()
+	if err := group.Shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	_ = group.Replace(func() ([]*Consumer, error) {
+		t.Error("consumers started after shutdown")
+		return nil, nil
+	})
+}
@@ -0,0 +1,161 @@
+package broker
+
+import (
+	"Contract/rabbit"
+	"github.com/streadway/amqp"
+	"sync"
+)
+
+// DeadLetter is a message from the dead-letter queue together with the reason it got there
+type DeadLetter struct {
+	CorrelationID string     `json:"correlationId"`
+	Exchange      string     `json:"exchange"`
+	RoutingKey    string     `json:"routingKey"`
+	Reason        string     `json:"reason"`
+	Headers       amqp.Table `json:"headers"`
+	Body          string     `json:"body"`
+}
+
+// DeadLetterQueue lets operators inspect and requeue rejected messages.
+// Operations are serialized, so messages taken by one call are not seen by another
+type DeadLetterQueue struct {
+	mu      sync.Mutex
+	channel *amqp.Channel
+	queue   string
+}
+
+func NewDeadLetterQueue(queue string) *DeadLetterQueue {
+	return &DeadLetterQueue{
+		queue: queue,
+	}
+}
+
+// Setup opens the channel used by the queue operations. It runs again on every reconnect
+func (q *DeadLetterQueue) Setup(conn *amqp.Connection, _ *amqp.Channel) error {
+	channel, err := conn.Channel()
+	if err != nil {
+		return err
+	}
+
+	q.mu.Lock()
+	q.channel = channel
+	q.mu.Unlock()
+
+	return nil
+}
+
+// Peek returns up to limit messages and puts them back to the queue
+func (q *DeadLetterQueue) Peek(limit int) ([]DeadLetter, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	deliveries, err := q.get(limit)
+
+	letters := make([]DeadLetter, 0, len(deliveries))
+	for _, d := range deliveries {
+		letters = append(letters, toDeadLetter(d))
+	}
+
+	if nackErr := q.nackAll(deliveries); err == nil {
+		err = nackErr
+	}
+
+	return letters, err
+}
+
+// Requeue republishes up to limit messages to the exchange they were rejected from
+// as new messages, so their delivery count starts over. It returns the number of requeued messages
+func (q *DeadLetterQueue) Requeue(limit int) (int, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	deliveries, err := q.get(limit)
+	if err != nil {
+		_ = q.nackAll(deliveries)
+		return 0, err
+	}
+
+	for i, d := range deliveries {
+		letter := toDeadLetter(d)
+
+		err = q.channel.Publish(letter.Exchange, letter.RoutingKey, false, false, republished(d))
+		if err != nil {
+			_ = q.nackAll(deliveries[i:])
+			return i, err
+		}
+
+		// the message is already republished, the rest goes back to the dead-letter queue
+		if err = d.Ack(false); err != nil {
+			_ = q.nackAll(deliveries[i+1:])
+			return i + 1, err
+		}
+	}
+
+	return len(deliveries), nil
+}
+
+func (q *DeadLetterQueue) get(limit int) ([]amqp.Delivery, error) {
+	var deliveries []amqp.Delivery
+
+	if q.channel == nil {
+		return nil, rabbit.ErrNotConnected
+	}
+
+	for len(deliveries) < limit {
+		d, ok, err := q.channel.Get(q.queue, false)
+		if err != nil {
+			return deliveries, err
+		}
+		if !ok {
+			break
+		}
+		deliveries = append(deliveries, d)
+	}
+
+	return deliveries, nil
+}
+
+func (q *DeadLetterQueue) nackAll(deliveries []amqp.Delivery) error {
+	var err error
+	for _, d := range deliveries {
+		if nackErr := d.Nack(false, true); nackErr != nil && err == nil {
+			err = nackErr
+		}
+	}
+	return err
+}
+
+// toDeadLetter reads the original exchange, routing key and reason from the x-death header
+func toDeadLetter(d amqp.Delivery) DeadLetter {
+	letter := DeadLetter{
+		CorrelationID: d.CorrelationId,
+		Exchange:      d.Exchange,
+		RoutingKey:    d.RoutingKey,
+		Headers:       d.Headers,
+		Body:          string(d.Body),
+	}
+
+	deaths, ok := d.Headers["x-death"].([]interface{})
+	if !ok || len(deaths) == 0 {
+		return letter
+	}
+
+	death, ok := deaths[0].(amqp.Table)
+	if !ok {
+		return letter
+	}
+
+	if exchange, ok := death["exchange"].(string); ok {
+		letter.Exchange = exchange
+	}
+	if keys, ok := death["routing-keys"].([]interface{}); ok && len(keys) > 0 {
+		if key, ok := keys[0].(string); ok {
+			letter.RoutingKey = key
+		}
+	}
+	if reason, ok := death["reason"].(string); ok {
+		letter.Reason = reason
+	}
+
+	return letter
+}
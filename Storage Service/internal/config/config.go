@@ -1,6 +1,7 @@
 package config
 
 import (
+	"Contract/rabbit"
 	"database/sql"
 	"fmt"
 	"github.com/spf13/viper"
@@ -9,17 +10,46 @@ import (
 	"time"
 )
 
+// RabbitMQConfig describes the connection and the consumers. MinBackoff and MaxBackoff bound the waits
+// between reconnects, RetryMinBackoff and RetryMaxBackoff the waits between retries of a failed message
 type RabbitMQConfig struct {
-	Host     string
-	Port     string
-	Username string
-	Password string
+	Host            string
+	Port            string
+	Username        string
+	Password        string
+	MaxRetries      int
+	Workers         int
+	Prefetch        int
+	MinBackoff      time.Duration
+	MaxBackoff      time.Duration
+	RetryMinBackoff time.Duration
+	RetryMaxBackoff time.Duration
+}
+
+// OutboxConfig describes where and how often the outbox relay publishes store events.
+// Published events are removed once they are older than Retention, a zero Retention keeps them forever
+type OutboxConfig struct {
+	Exchange        rabbit.Exchange
+	PollInterval    time.Duration
+	BatchSize       int
+	ConfirmTimeout  time.Duration
+	Retention       time.Duration
+	CleanupInterval time.Duration
+}
+
+// PurgeConfig describes how long deleted stores and versions are kept before they are removed for good.
+// A zero Retention turns purging off
+type PurgeConfig struct {
+	Retention time.Duration
+	Interval  time.Duration
 }
 
-type GatewayConfig struct {
-	Host string
-	Port string
-	Path string
+// AdminServerConfig describes the admin server. Token guards the dead-letter endpoints,
+// they are turned off when it is empty
+type AdminServerConfig struct {
+	Host  string
+	Port  string
+	Token string
 }
 
 type DB struct {
@@ -77,24 +107,96 @@ func (cfg *Configurator) GetEnvironment(logger *zap.Logger) AppEnvironment {
 	return AppEnvironment(env)
 }
 
-func (cfg *Configurator) GetRabbitMQConfig() *RabbitMQConfig {
-	return &RabbitMQConfig{
-		Password: viper.GetString("rabbit.password"),
-		Username: viper.GetString("rabbit.username"),
-		Port:     viper.GetString("rabbit.port"),
-		Host:     viper.GetString("rabbit.host"),
+// GetRabbitMQConfig reads the rabbit section. Prefetch must be at least 1, since the broker
+// treats 0 as no limit and workers buffer up to prefetch messages each
+func (cfg *Configurator) GetRabbitMQConfig() (*RabbitMQConfig, error) {
+	mqConfig := &RabbitMQConfig{
+		Password:   viper.GetString("rabbit.password"),
+		Username:   viper.GetString("rabbit.username"),
+		Port:       viper.GetString("rabbit.port"),
+		Host:       viper.GetString("rabbit.host"),
+		MaxRetries: viper.GetInt("rabbit.maxRetries"),
+		Workers:    viper.GetInt("rabbit.workers"),
+		Prefetch:   viper.GetInt("rabbit.prefetch"),
+		MinBackoff: viper.GetDuration("rabbit.reconnect.minBackoff"),
+		MaxBackoff: viper.GetDuration("rabbit.reconnect.maxBackoff"),
+
+		RetryMinBackoff: viper.GetDuration("rabbit.retry.minBackoff"),
+		RetryMaxBackoff: viper.GetDuration("rabbit.retry.maxBackoff"),
+	}
+
+	if mqConfig.Prefetch < 1 {
+		return nil, fmt.Errorf("rabbit.prefetch must be at least 1, got %d", mqConfig.Prefetch)
 	}
+
+	return mqConfig, nil
 }
 
-func (cfg *Configurator) GetGatewayServerUrl() string {
-	gtwConfig := &GatewayConfig{
-		Port: viper.GetString("gateway.port"),
-		Host: viper.GetString("gateway.host"),
-		Path: viper.GetString("gateway.path"),
+func (cfg *Configurator) GetRabbitTopologyConfig() (*rabbit.Topology, error) {
+	var queues []rabbit.Queue
+	if err := viper.UnmarshalKey("rabbit.queues", &queues); err != nil {
+		return nil, fmt.Errorf("failed to read rabbit queues: %w", err)
 	}
-	gatewayURL := fmt.Sprintf("http://%s:%s/%s", gtwConfig.Host, gtwConfig.Port, gtwConfig.Path)
 
-	return gatewayURL
+	return &rabbit.Topology{
+		Exchange: rabbit.Exchange{
+			Name:    viper.GetString("rabbit.exchange.name"),
+			Kind:    viper.GetString("rabbit.exchange.type"),
+			Durable: viper.GetBool("rabbit.exchange.durable"),
+		},
+		Queues:             queues,
+		DeadLetterExchange: viper.GetString("rabbit.deadLetter.exchange"),
+		DeadLetterQueue:    viper.GetString("rabbit.deadLetter.queue"),
+		Persistent:         viper.GetBool("rabbit.persistent"),
+	}, nil
+}
+
+// AdminTokenVariable overrides admin.token, so the token doesn't have to be kept in the config file
+const AdminTokenVariable = "ADMIN_TOKEN"
+
+func (cfg *Configurator) GetAdminServerConfig() *AdminServerConfig {
+	token := os.Getenv(AdminTokenVariable)
+	if token == "" {
+		token = viper.GetString("admin.token")
+	}
+
+	return &AdminServerConfig{
+		Host:  viper.GetString("admin.host"),
+		Port:  viper.GetString("admin.port"),
+		Token: token,
+	}
+}
+
+func (cfg *Configurator) GetOutboxConfig() *OutboxConfig {
+	return &OutboxConfig{
+		Exchange: rabbit.Exchange{
+			Name:    viper.GetString("outbox.exchange.name"),
+			Kind:    viper.GetString("outbox.exchange.type"),
+			Durable: viper.GetBool("outbox.exchange.durable"),
+		},
+		PollInterval:    viper.GetDuration("outbox.pollInterval"),
+		BatchSize:       viper.GetInt("outbox.batchSize"),
+		ConfirmTimeout:  viper.GetDuration("outbox.confirmTimeout"),
+		Retention:       viper.GetDuration("outbox.retention"),
+		CleanupInterval: viper.GetDuration("outbox.cleanupInterval"),
+	}
+}
+
+func (cfg *Configurator) GetPurgeConfig() *PurgeConfig {
+	return &PurgeConfig{
+		Retention: viper.GetDuration("purge.retention"),
+		Interval:  viper.GetDuration("purge.interval"),
+	}
+}
+
+// GetShutdownTimeout returns how long in-flight messages may take to finish on shutdown
+func (cfg *Configurator) GetShutdownTimeout() time.Duration {
+	return viper.GetDuration("shutdown.timeout")
+}
+
+// GetAdminShutdownTimeout returns how long admin requests in progress may take to finish on shutdown
+func (cfg *Configurator) GetAdminShutdownTimeout() time.Duration {
+	return viper.GetDuration("shutdown.adminTimeout")
 }
 
 func (cfg *Configurator) GetAMQPConnectionURL(rabbitCfg *RabbitMQConfig) string {
@@ -0,0 +1,141 @@
+package handler
+
+import (
+	"Contract"
+	"context"
+	"github.com/streadway/amqp"
+)
+
+// message is a decoded request together with the delivery it came in, replies go to its reply_to queue
+type message struct {
+	delivery amqp.Delivery
+	envelope *Envelope
+}
+
+// actions routes every action of the contract to its MessageHandler method
+type actions struct {
+	*MessageHandler
+}
+
+var _ contract.Handler[message] = actions{}
+
+func (a actions) CreateStore(ctx context.Context, m message) error {
+	return a.handleCreateStore(ctx, m.delivery, m.envelope)
+}
+
+func (a actions) CreateStoreVersion(ctx context.Context, m message) error {
+	return a.handleCreateStoreVersion(ctx, m.delivery, m.envelope)
+}
+
+func (a actions) DeleteStore(ctx context.Context, m message) error {
+	return a.handleDeleteStore(ctx, m.delivery, m.envelope)
+}
+
+func (a actions) DeleteStoreVersion(ctx context.Context, m message) error {
+	return a.handleDeleteStoreVersion(ctx, m.delivery, m.envelope)
+}
+
+func (a actions) GetStore(ctx context.Context, m message) error {
+	return a.handleGetStore(ctx, m.delivery, m.envelope)
+}
+
+func (a actions) GetStoreDiff(ctx context.Context, m message) error {
+	return a.handleGetStoreDiff(ctx, m.delivery, m.envelope)
+}
+
+func (a actions) GetStoreHistory(ctx context.Context, m message) error {
+	return a.handleGetStoreHistory(ctx, m.delivery, m.envelope)
+}
+
+func (a actions) GetStoreVersion(ctx context.Context, m message) error {
+	return a.handleGetStoreVersion(ctx, m.delivery, m.envelope)
+}
+
+func (a actions) ListCities(ctx context.Context, m message) error {
+	return a.handleListCities(ctx, m.delivery, m.envelope)
+}
+
+func (a actions) ListStores(ctx context.Context, m message) error {
+	return a.handleListStores(ctx, m.delivery, m.envelope)
+}
+
+func (a actions) RestoreStoreVersion(ctx context.Context, m message) error {
+	return a.handleRestoreStoreVersion(ctx, m.delivery, m.envelope)
+}
+
+func (a actions) SearchStores(ctx context.Context, m message) error {
+	return a.handleSearchStores(ctx, m.delivery, m.envelope)
+}
+
+func (a actions) UndeleteStore(ctx context.Context, m message) error {
+	return a.handleUndeleteStore(ctx, m.delivery, m.envelope)
+}
+
+func (a actions) UndeleteStoreVersion(ctx context.Context, m message) error {
+	return a.handleUndeleteStoreVersion(ctx, m.delivery, m.envelope)
+}
+
+// payloadCheck carries a decoded payload to its validator and the validated data back.
+// payload is nil when an optional payload is left out
+type payloadCheck struct {
+	payload interface{}
+	data    interface{}
+	fields  []contract.FieldError
+}
+
+// payloadValidators validates the payload of every action. Actions without payload have nothing to check
+type payloadValidators struct{}
+
+var _ contract.Handler[*payloadCheck] = payloadValidators{}
+
+func (payloadValidators) CreateStore(_ context.Context, c *payloadCheck) error {
+	c.data, c.fields = validateStoreData(c.payload)
+	return nil
+}
+
+func (payloadValidators) CreateStoreVersion(_ context.Context, c *payloadCheck) error {
+	c.data, c.fields = validateStoreVersionData(c.payload)
+	return nil
+}
+
+func (payloadValidators) DeleteStore(context.Context, *payloadCheck) error { return nil }
+
+func (payloadValidators) DeleteStoreVersion(context.Context, *payloadCheck) error { return nil }
+
+func (payloadValidators) GetStore(_ context.Context, c *payloadCheck) error {
+	c.data, c.fields = validatePointInTimeData(c.payload)
+	return nil
+}
+
+func (payloadValidators) GetStoreDiff(_ context.Context, c *payloadCheck) error {
+	c.data, c.fields = validateStoreDiffData(c.payload)
+	return nil
+}
+
+func (payloadValidators) GetStoreHistory(_ context.Context, c *payloadCheck) error {
+	c.data, c.fields = validateStoreHistoryData(c.payload)
+	return nil
+}
+
+func (payloadValidators) GetStoreVersion(context.Context, *payloadCheck) error { return nil }
+
+func (payloadValidators) ListCities(_ context.Context, c *payloadCheck) error {
+	c.data, c.fields = validateListCitiesData(c.payload)
+	return nil
+}
+
+func (payloadValidators) ListStores(_ context.Context, c *payloadCheck) error {
+	c.data, c.fields = validateListStoresData(c.payload)
+	return nil
+}
+
+func (payloadValidators) RestoreStoreVersion(context.Context, *payloadCheck) error { return nil }
+
+func (payloadValidators) SearchStores(_ context.Context, c *payloadCheck) error {
+	c.data, c.fields = validateSearchStoresData(c.payload)
+	return nil
+}
+
+func (payloadValidators) UndeleteStore(context.Context, *payloadCheck) error { return nil }
+
+func (payloadValidators) UndeleteStoreVersion(context.Context, *payloadCheck) error { return nil }
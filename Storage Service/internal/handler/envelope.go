@@ -0,0 +1,362 @@
+package handler
+
+import (
+	"Contract"
+	"StorageService/internal/model"
+	"StorageService/internal/service"
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Envelope is a decoded and validated request from the gateway
+type Envelope struct {
+	*contract.Request
+
+	data interface{}
+}
+
+// ValidationError is returned for messages that can never be processed.
+// Code is sent to the gateway, Fields tells which fields are wrong
+type ValidationError struct {
+	Code    string
+	Message string
+	Fields  []contract.FieldError
+}
+
+func (e *ValidationError) Error() string {
+	if len(e.Fields) == 0 {
+		return e.Message
+	}
+
+	fields := make([]string, 0, len(e.Fields))
+	for _, field := range e.Fields {
+		fields = append(fields, field.Field+": "+field.Message)
+	}
+
+	return e.Message + " (" + strings.Join(fields, ", ") + ")"
+}
+
+// storeSorts maps the sort orders of list_stores to the columns stores are listed by
+var storeSorts = map[string]string{
+	"":                          model.StoreSortCreatedAt,
+	contract.StoreSortCreatedAt: model.StoreSortCreatedAt,
+	contract.StoreSortName:      model.StoreSortName,
+}
+
+// DecodeEnvelope decodes the message body in the content type and checks the fields
+// required by its action. Errors are always *ValidationError
+func DecodeEnvelope(body []byte, contentType string) (*Envelope, error) {
+	request, err := contract.DecodeRequest(body, contentType)
+	if err != nil {
+		return nil, &ValidationError{Code: contract.CodeBadRequest, Message: "message is not a valid envelope: " + err.Error()}
+	}
+
+	if request.SchemaVersion != contract.SchemaVersion {
+		return nil, &ValidationError{
+			Code:    contract.CodeUnsupportedSchema,
+			Message: fmt.Sprintf("unsupported schema version %d, expected %d", request.SchemaVersion, contract.SchemaVersion),
+		}
+	}
+
+	spec, ok := contract.Spec(request.Action)
+	if !ok {
+		return nil, &ValidationError{Code: contract.CodeUnknownAction, Message: fmt.Sprintf("unknown action %q", request.Action)}
+	}
+
+	envelope := &Envelope{Request: request}
+
+	var fields []contract.FieldError
+	fields = requireField(fields, spec.StoreID, "storeId", request.StoreID)
+	fields = requireField(fields, spec.VersionID, "versionId", request.VersionID)
+	fields = requireField(fields, spec.Login, "userLogin", request.UserLogin)
+
+	if spec.Payload != nil {
+		payload, dataFields := decodePayload(request, spec)
+		if dataFields == nil {
+			// the payload has the type of the action spec, the validator of the action knows it
+			check := &payloadCheck{payload: payload}
+			if err := contract.Dispatch[*payloadCheck](context.Background(), payloadValidators{}, request.Action, check); err != nil {
+				return nil, &ValidationError{Code: contract.CodeUnknownAction, Message: fmt.Sprintf("unknown action %q", request.Action)}
+			}
+			envelope.data, dataFields = check.data, check.fields
+		}
+		fields = append(fields, dataFields...)
+	}
+
+	if len(fields) > 0 {
+		return nil, &ValidationError{
+			Code:    contract.CodeBadRequest,
+			Message: "invalid " + request.Action + " message",
+			Fields:  fields,
+		}
+	}
+
+	return envelope, nil
+}
+
+// StoreData returns the payload of create_store messages
+func (e *Envelope) StoreData() *contract.CreateStoreData {
+	data, _ := e.data.(*contract.CreateStoreData)
+	return data
+}
+
+// StoreVersionData returns the payload of create_store_version messages
+func (e *Envelope) StoreVersionData() *contract.CreateStoreVersionData {
+	data, _ := e.data.(*contract.CreateStoreVersionData)
+	return data
+}
+
+// StoreDiffData returns the payload of get_store_diff messages
+func (e *Envelope) StoreDiffData() *contract.StoreDiffData {
+	data, _ := e.data.(*contract.StoreDiffData)
+	return data
+}
+
+// SearchStoresData returns the payload of search_stores messages with the default limit filled in
+func (e *Envelope) SearchStoresData() *contract.SearchStoresData {
+	data, _ := e.data.(*contract.SearchStoresData)
+	return data
+}
+
+// ListCitiesData returns the payload of list_cities messages with the default limit filled in
+func (e *Envelope) ListCitiesData() *contract.ListCitiesData {
+	data, _ := e.data.(*contract.ListCitiesData)
+	return data
+}
+
+// StoreQuery returns the query of list_stores messages with the defaults filled in
+func (e *Envelope) StoreQuery() service.StoreQuery {
+	query, _ := e.data.(service.StoreQuery)
+	return query
+}
+
+// HistoryQuery returns the query of get_store_history messages with the defaults filled in
+func (e *Envelope) HistoryQuery() service.HistoryQuery {
+	query, _ := e.data.(service.HistoryQuery)
+	return query
+}
+
+// PointInTime returns the time get_store messages read the store at, ok is false
+// when the current state is requested
+func (e *Envelope) PointInTime() (at time.Time, ok bool) {
+	at, ok = e.data.(time.Time)
+	return at, ok
+}
+
+func validateStoreData(payload interface{}) (interface{}, []contract.FieldError) {
+	data := payload.(*contract.CreateStoreData)
+
+	var fields []contract.FieldError
+	fields = requireField(fields, true, "data.name", data.Name)
+	fields = requireField(fields, data.StructuredAddress == nil, "data.address", data.Address)
+	fields = requireAddressParts(fields, data.StructuredAddress)
+	fields = requireField(fields, true, "data.ownerName", data.OwnerName)
+	fields = requireField(fields, true, "data.openingTime", data.OpeningTime)
+	fields = requireField(fields, true, "data.closingTime", data.ClosingTime)
+	return data, fields
+}
+
+func validateStoreVersionData(payload interface{}) (interface{}, []contract.FieldError) {
+	data := payload.(*contract.CreateStoreVersionData)
+
+	// empty fields are carried over from the previous version, but at least one must be given
+	var fields []contract.FieldError
+	if strings.TrimSpace(data.Name+data.Address+data.OwnerName+data.OpeningTime+data.ClosingTime) == "" && data.StructuredAddress == nil {
+		fields = append(fields, contract.FieldError{Field: "data", Message: "at least one field is required"})
+	}
+	fields = requireAddressParts(fields, data.StructuredAddress)
+	for _, revision := range data.ExpectedRevisions {
+		if revision < 1 {
+			fields = append(fields, contract.FieldError{Field: "data.expectedRevisions", Message: "must be positive"})
+			break
+		}
+	}
+	if data.IfMatchAny && len(data.ExpectedRevisions) > 0 {
+		fields = append(fields, contract.FieldError{Field: "data.ifMatchAny", Message: "can't be combined with expectedRevisions"})
+	}
+	return data, fields
+}
+
+func validateStoreDiffData(payload interface{}) (interface{}, []contract.FieldError) {
+	data := payload.(*contract.StoreDiffData)
+
+	var fields []contract.FieldError
+	fields = requireField(fields, !data.Compact, "data.fromVersionId", data.FromVersionID)
+	fields = requireField(fields, !data.Compact, "data.toVersionId", data.ToVersionID)
+	return data, fields
+}
+
+// validateListStoresData turns the optional list_stores payload into a service.StoreQuery,
+// stores are listed by creation time, oldest first, without payload
+func validateListStoresData(payload interface{}) (interface{}, []contract.FieldError) {
+	var data contract.ListStoresData
+	if payload != nil {
+		data = *payload.(*contract.ListStoresData)
+	}
+
+	query := service.StoreQuery{
+		CreatorLogin: strings.TrimSpace(data.CreatorLogin),
+		NamePrefix:   strings.TrimSpace(data.NamePrefix),
+		City:         strings.TrimSpace(data.City),
+		Limit:        data.Limit,
+		Cursor:       data.Cursor,
+	}
+
+	var fields []contract.FieldError
+
+	sortBy, ok := storeSorts[data.Sort]
+	if !ok {
+		fields = append(fields, contract.FieldError{Field: "data.sort", Message: "must be name or created_at"})
+	}
+	query.SortBy = sortBy
+
+	switch data.Order {
+	case "", contract.OrderAsc:
+	case contract.OrderDesc:
+		query.Descending = true
+	default:
+		fields = append(fields, contract.FieldError{Field: "data.order", Message: "must be asc or desc"})
+	}
+
+	query.Limit, fields = listLimit(fields, query.Limit)
+
+	query.CreatedFrom, fields = parseTimestamp(fields, "data.createdFrom", data.CreatedFrom)
+	query.CreatedTo, fields = parseTimestamp(fields, "data.createdTo", data.CreatedTo)
+
+	return query, fields
+}
+
+// validateListCitiesData fills in the default limit, the payload of list_cities is optional
+func validateListCitiesData(payload interface{}) (interface{}, []contract.FieldError) {
+	data := &contract.ListCitiesData{}
+	if payload != nil {
+		data = payload.(*contract.ListCitiesData)
+	}
+
+	var fields []contract.FieldError
+	data.Limit, fields = listLimit(fields, data.Limit)
+	return data, fields
+}
+
+func validateSearchStoresData(payload interface{}) (interface{}, []contract.FieldError) {
+	data := payload.(*contract.SearchStoresData)
+
+	var fields []contract.FieldError
+	fields = requireField(fields, true, "data.query", data.Query)
+	if len(strings.Fields(data.Query)) > contract.MaxSearchWords {
+		fields = append(fields, contract.FieldError{Field: "data.query", Message: fmt.Sprintf("must have at most %d words", contract.MaxSearchWords)})
+	}
+	data.Limit, fields = listLimit(fields, data.Limit)
+	return data, fields
+}
+
+// listLimit returns the page size, contract.DefaultListLimit when it is 0
+func listLimit(fields []contract.FieldError, limit int) (int, []contract.FieldError) {
+	if limit == 0 {
+		return contract.DefaultListLimit, fields
+	}
+	if limit < 0 || limit > contract.MaxListLimit {
+		return limit, append(fields, contract.FieldError{Field: "data.limit", Message: fmt.Sprintf("must be between 1 and %d", contract.MaxListLimit)})
+	}
+	return limit, fields
+}
+
+// validateStoreHistoryData turns the optional get_store_history payload into a service.HistoryQuery,
+// versions are listed newest first without payload
+func validateStoreHistoryData(payload interface{}) (interface{}, []contract.FieldError) {
+	var data contract.StoreHistoryData
+	if payload != nil {
+		data = *payload.(*contract.StoreHistoryData)
+	}
+
+	query := service.HistoryQuery{
+		CreatorLogin: strings.TrimSpace(data.CreatorLogin),
+		Descending:   true,
+		Cursor:       data.Cursor,
+	}
+
+	var fields []contract.FieldError
+
+	switch data.Order {
+	case "", contract.OrderDesc:
+	case contract.OrderAsc:
+		query.Descending = false
+	default:
+		fields = append(fields, contract.FieldError{Field: "data.order", Message: "must be asc or desc"})
+	}
+
+	query.Limit, fields = listLimit(fields, data.Limit)
+	query.At, fields = parseTimestamp(fields, "data.at", data.At)
+	query.CreatedFrom, fields = parseTimestamp(fields, "data.createdFrom", data.CreatedFrom)
+	query.CreatedTo, fields = parseTimestamp(fields, "data.createdTo", data.CreatedTo)
+
+	return query, fields
+}
+
+// parseTimestamp parses an optional RFC 3339 field, zero time when it is empty
+func parseTimestamp(fields []contract.FieldError, name, value string) (time.Time, []contract.FieldError) {
+	if value == "" {
+		return time.Time{}, fields
+	}
+
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, append(fields, contract.FieldError{Field: name, Message: "must be an RFC 3339 timestamp"})
+	}
+
+	return t, fields
+}
+
+// validatePointInTimeData turns the optional point-in-time payload into a time.Time, nil without payload
+func validatePointInTimeData(payload interface{}) (interface{}, []contract.FieldError) {
+	if payload == nil {
+		return nil, nil
+	}
+	data := payload.(*contract.PointInTimeData)
+
+	at, err := time.Parse(time.RFC3339, data.At)
+	if err != nil {
+		return nil, []contract.FieldError{{Field: "data.at", Message: "must be an RFC 3339 timestamp"}}
+	}
+
+	return at, nil
+}
+
+// decodePayload decodes the request payload into the type of the action spec, it returns nil fields
+// when the payload is well formed and a nil payload when an optional payload is left out
+func decodePayload(request *contract.Request, spec contract.ActionSpec) (interface{}, []contract.FieldError) {
+	if !request.HasData() {
+		if spec.PayloadOptional {
+			return nil, nil
+		}
+		return nil, []contract.FieldError{{Field: "data", Message: "is required"}}
+	}
+
+	payload := spec.NewPayload()
+	if err := request.DecodeData(payload); err != nil {
+		return nil, []contract.FieldError{{Field: "data", Message: err.Error()}}
+	}
+
+	return payload, nil
+}
+
+// requireAddressParts checks the parts of a structured address, nil addresses are not checked
+func requireAddressParts(fields []contract.FieldError, address *contract.Address) []contract.FieldError {
+	if address == nil {
+		return fields
+	}
+
+	fields = requireField(fields, true, "data.structuredAddress.city", address.City)
+	fields = requireField(fields, true, "data.structuredAddress.street", address.Street)
+	fields = requireField(fields, true, "data.structuredAddress.house", address.House)
+	return fields
+}
+
+func requireField(fields []contract.FieldError, required bool, name, value string) []contract.FieldError {
+	if required && strings.TrimSpace(value) == "" {
+		return append(fields, contract.FieldError{Field: name, Message: "is required"})
+	}
+	return fields
+}
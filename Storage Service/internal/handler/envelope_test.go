@@ -0,0 +1,124 @@
+package handler
+
+import (
+	"Contract"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// validRequests holds a well-formed request for every action understood by the storage service
+var validRequests = map[string]contract.Request{
+	contract.ActionCreateStore: {
+		UserLogin: "user",
+		Payload: contract.CreateStoreData{
+			Name:        "Bakery",
+			Address:     "Moscow, Lenina, 1",
+			OwnerName:   "Owner",
+			OpeningTime: "09:00",
+			ClosingTime: "21:00",
+		},
+	},
+	contract.ActionCreateStoreVersion: {
+		StoreID:   "store",
+		UserLogin: "user",
+		Payload:   contract.CreateStoreVersionData{OwnerName: "Owner", OpeningTime: "09:00", ClosingTime: "21:00"},
+	},
+	contract.ActionDeleteStore:          {StoreID: "store", UserLogin: "user"},
+	contract.ActionDeleteStoreVersion:   {StoreID: "store", VersionID: "version", UserLogin: "user"},
+	contract.ActionRestoreStoreVersion:  {StoreID: "store", VersionID: "version", UserLogin: "user"},
+	contract.ActionUndeleteStore:        {StoreID: "store", UserLogin: "user"},
+	contract.ActionUndeleteStoreVersion: {StoreID: "store", VersionID: "version", UserLogin: "user"},
+	contract.ActionGetStore:             {StoreID: "store"},
+	contract.ActionGetStoreDiff: {
+		StoreID: "store",
+		Payload: contract.StoreDiffData{FromVersionID: "from", ToVersionID: "to"},
+	},
+	contract.ActionGetStoreHistory: {StoreID: "store"},
+	contract.ActionGetStoreVersion: {StoreID: "store", VersionID: "version"},
+	contract.ActionListCities:      {},
+	contract.ActionListStores:      {},
+	contract.ActionSearchStores:    {Payload: contract.SearchStoresData{Query: "bakery"}},
+}
+
+func TestDecodeEnvelope(t *testing.T) {
+	for _, contentType := range []string{contract.ContentTypeJSON, contract.ContentTypeProtobuf} {
+		for action, request := range validRequests {
+			request.Action = action
+
+			body, err := contract.EncodeRequest(request, contentType)
+			if err != nil {
+				t.Fatalf("%s %s: encode: %v", contentType, action, err)
+			}
+
+			envelope, err := DecodeEnvelope(body, contentType)
+			if err != nil {
+				t.Errorf("%s %s: %v", contentType, action, err)
+				continue
+			}
+			if envelope.Action != action {
+				t.Errorf("%s %s: decoded action %q", contentType, action, envelope.Action)
+			}
+		}
+	}
+}
+
+func TestDecodeEnvelopeRejects(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		code string
+	}{
+		{"not json", `{`, contract.CodeBadRequest},
+		{"unknown action", `{"action":"drop_stores"}`, contract.CodeUnknownAction},
+		{"future schema", `{"schemaVersion":2,"action":"get_store","storeId":"store"}`, contract.CodeUnsupportedSchema},
+		{"missing store id", `{"action":"get_store"}`, contract.CodeBadRequest},
+		{"missing diff versions", `{"action":"get_store_diff","storeId":"store","data":{}}`, contract.CodeBadRequest},
+		{"unknown data field", `{"action":"get_store_diff","storeId":"store","data":{"fromVersionId":"a","toVersionId":"b","page":2}}`, contract.CodeBadRequest},
+		{"too many search words", `{"action":"search_stores","data":{"query":"` + strings.Repeat("bakery ", contract.MaxSearchWords+1) + `"}}`, contract.CodeBadRequest},
+	}
+
+	for _, tt := range tests {
+		_, err := DecodeEnvelope([]byte(tt.body), contract.ContentTypeJSON)
+
+		var validationErr *ValidationError
+		if !errors.As(err, &validationErr) {
+			t.Errorf("%s: got %v, want *ValidationError", tt.name, err)
+			continue
+		}
+		if validationErr.Code != tt.code {
+			t.Errorf("%s: got code %q, want %q", tt.name, validationErr.Code, tt.code)
+		}
+	}
+}
+
+func TestDecodeEnvelopeCompactDiff(t *testing.T) {
+	body, _ := json.Marshal(map[string]interface{}{
+		"action":  contract.ActionGetStoreDiff,
+		"storeId": "store",
+		"data":    contract.StoreDiffData{Compact: true},
+	})
+
+	envelope, err := DecodeEnvelope(body, contract.ContentTypeJSON)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !envelope.StoreDiffData().Compact {
+		t.Error("compact flag is lost")
+	}
+}
+
+// TestEveryActionHasTestRequest fails when an action is added to the contract without a test request here.
+// Handlers and payload validators can't be missing, actions and payloadValidators don't compile without them
+func TestEveryActionHasTestRequest(t *testing.T) {
+	for _, action := range contract.Actions() {
+		if _, ok := validRequests[action]; !ok {
+			t.Errorf("%s has no test request", action)
+		}
+	}
+
+	if len(validRequests) != len(contract.Actions()) {
+		t.Errorf("%d test requests for %d actions", len(validRequests), len(contract.Actions()))
+	}
+}
@@ -1,357 +1,599 @@
 package handler
 
 import (
+	"Contract"
+	"StorageService/internal/broker"
 	"StorageService/internal/model"
+	"StorageService/internal/requestid"
 	"StorageService/internal/service"
-	"bytes"
-	"encoding/json"
+	"context"
+	"errors"
 	"fmt"
 	"github.com/streadway/amqp"
 	"go.uber.org/zap"
-	"net/http"
+	"strings"
+	"time"
 )
 
 type StoreService interface {
-	CreateStore(data service.Store, login string) error
-	CreateStoreVersion(data service.StoreVersion, storeId string, login string) error
-	DeleteStore(storeId, login string) error
-	DeleteStoreVersion(storeId, versionId, login string) error
-	GetStoreByID(storeId string) (*model.Store, error)
-	GetStoreVersionHistory(storeId string) ([]*model.StoreVersion, error)
-	GetStoreVersionByID(storeId, versionId string) (*model.StoreVersion, error)
+	CreateStore(ctx context.Context, data service.Store, login, idempotencyKey string) (*model.Store, error)
+	CreateStoreVersion(ctx context.Context, data service.StoreVersion, storeId, login, idempotencyKey string) (*model.StoreVersion, error)
+	RestoreStoreVersion(ctx context.Context, storeId, versionId, login string) (*model.StoreVersion, error)
+	DeleteStore(ctx context.Context, storeId, login string) error
+	DeleteStoreVersion(ctx context.Context, storeId, versionId, login string) error
+	UndeleteStore(ctx context.Context, storeId, login string) error
+	UndeleteStoreVersion(ctx context.Context, storeId, versionId, login string) error
+	GetStoreByID(ctx context.Context, storeId string) (*model.Store, error)
+	GetStoreVersionHistory(ctx context.Context, storeId string) ([]*model.StoreVersion, error)
+	GetStoreAt(ctx context.Context, storeId string, at time.Time) (*model.Store, error)
+	GetStoreVersionHistoryPage(ctx context.Context, storeId string, query service.HistoryQuery) (*service.StoreVersionPage, error)
+	GetStoreDiff(ctx context.Context, storeId, fromVersionId, toVersionId string) (*service.StoreDiff, error)
+	GetStoreHistoryDiff(ctx context.Context, storeId string) ([]*service.StoreDiff, error)
+	GetStoreVersionByID(ctx context.Context, storeId, versionId string) (*model.StoreVersion, error)
+	ListStores(ctx context.Context, query service.StoreQuery) (*service.StorePage, error)
+	ListCities(ctx context.Context, limit int, cursor string) (*service.CityPage, error)
+	SearchStores(ctx context.Context, text string, limit int) ([]*model.StoreSearchResult, error)
 }
 
-type StoreFromMessage struct {
-	Name        string `json:"name" binding:"required"`
-	Address     string `json:"address" binding:"required"`
-	OwnerName   string `json:"ownerName" binding:"required"`
-	OpeningTime string `json:"openingTime" binding:"required"`
-	ClosingTime string `json:"closingTime" binding:"required"`
-}
-
-type StoreVersionFromMessage struct {
-	OwnerName   string `json:"ownerName" binding:"required"`
-	OpeningTime string `json:"openingTime" binding:"required"`
-	ClosingTime string `json:"closingTime" binding:"required"`
-}
-
-type Message struct {
-	Action    string          `json:"action"`
-	Data      json.RawMessage `json:"data"`
-	StoreID   string          `json:"storeId"`
-	UserLogin string          `json:"userLogin"`
-	VersionID string          `json:"versionId"`
+// Publisher is used to send replies back to the queue named in the reply_to property
+type Publisher interface {
+	Publish(exchange, key string, mandatory, immediate bool, msg amqp.Publishing) error
 }
 
 type MessageHandler struct {
 	storeService StoreService
-	gatewayUrl   string
+	publisher    Publisher
 	logger       *zap.Logger
 }
 
-func NewMessageHandler(storeService StoreService, gatewayUrl string, logger *zap.Logger) *MessageHandler {
+func NewMessageHandler(storeService StoreService, publisher Publisher, logger *zap.Logger) *MessageHandler {
 	return &MessageHandler{
 		storeService: storeService,
-		gatewayUrl:   gatewayUrl,
+		publisher:    publisher,
 		logger:       logger,
 	}
 }
 
-func sendResponseToGateway(url string, payload interface{}) error {
-	jsonPayload, err := json.Marshal(payload)
-	if err != nil {
-		return err
-	}
+// HandleMessage processes the message and replies to the gateway. Errors returned are either
+// broker.ErrMalformedMessage for messages that can't be processed or internal failures worth retrying
+func (h *MessageHandler) HandleMessage(ctx context.Context, msg amqp.Delivery) error {
+	ctx = requestid.NewContext(ctx, extractRequestID(msg))
+
+	requestid.Logger(ctx, h.logger).Info("Received message", zap.ByteString("message", msg.Body))
 
-	resp, err := http.Post(url, "application/json", bytes.NewBuffer(jsonPayload))
+	envelope, err := DecodeEnvelope(msg.Body, msg.ContentType)
 	if err != nil {
-		return err
+		var validationErr *ValidationError
+		errors.As(err, &validationErr)
+
+		requestid.Logger(ctx, h.logger).Warn("Rejected message", zap.Error(err))
+		h.sendValidationErrorReply(ctx, msg, validationErr)
+		return fmt.Errorf("%w: %v", broker.ErrMalformedMessage, err)
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	err = contract.Dispatch[message](ctx, actions{h}, envelope.Action, message{delivery: msg, envelope: envelope})
+	if errors.Is(err, contract.ErrUnknownAction) {
+		// DecodeEnvelope knows every action with a spec, this means the contract has no Dispatch case for it
+		h.sendErrorReply(ctx, msg, contract.CodeUnknownAction, "unknown action: "+envelope.Action)
+		return fmt.Errorf("%w: %v %q", broker.ErrMalformedMessage, err, envelope.Action)
 	}
 
-	return nil
+	return err
 }
 
-func (h *MessageHandler) HandleMessage(msg amqp.Delivery) {
-	h.logger.Info("Received message", zap.ByteString("message", msg.Body))
-
-	userLogin := extractLogin(msg)
-	action := extractAction(msg)
-
-	switch action {
-	case "delete_store":
-		h.handleDeleteStore(msg, userLogin)
-	case "delete_store_version":
-		h.handleDeleteStoreVersion(msg, userLogin)
-	case "create_store":
-		h.handleCreateStore(msg, userLogin)
-	case "create_store_version":
-		h.handleCreateStoreVersion(msg, userLogin)
-	case "get_store":
-		h.handleGetStore(msg)
-	case "get_store_history":
-		h.handleGetStoreHistory(msg)
-	case "get_store_version":
-		h.handleGetStoreVersion(msg)
-	default:
-		h.logger.Warn("Unknown action", zap.String("action", action))
-	}
+// HandleDeadLetter tells the gateway the message failed for good
+func (h *MessageHandler) HandleDeadLetter(msg amqp.Delivery, err error) {
+	ctx := requestid.NewContext(context.Background(), extractRequestID(msg))
+
+	requestid.Logger(ctx, h.logger).Error("Giving up on message", zap.Error(err))
+	h.sendErrorReply(ctx, msg, contract.CodeInternal, "failed to process request")
 }
 
-func (h *MessageHandler) handleDeleteStore(msg amqp.Delivery, userLogin string) {
-	storeId := extractStoreID(msg)
+// OrderingKey makes messages for the same store go to the same worker.
+// Malformed messages have no key, they are rejected by HandleMessage anyway
+func (h *MessageHandler) OrderingKey(msg amqp.Delivery) string {
+	request, err := contract.DecodeRequest(msg.Body, msg.ContentType)
+	if err != nil {
+		return ""
+	}
+	return request.StoreID
+}
 
-	err := h.storeService.DeleteStore(storeId, userLogin)
+func (h *MessageHandler) handleDeleteStore(ctx context.Context, msg amqp.Delivery, envelope *Envelope) error {
+	err := h.storeService.DeleteStore(ctx, envelope.StoreID, envelope.UserLogin)
 
 	if err != nil {
-		h.logger.Error("Failed to delete store", zap.Error(err))
+		requestid.Logger(ctx, h.logger).Error("Failed to delete store", zap.Error(err))
+		return h.sendServiceErrorReply(ctx, msg, err)
+	}
 
-		err = sendErrorResponseToGateway(h.gatewayUrl, err.Error())
-		if err != nil {
-			h.logger.Error("Failed to send error response to Gateway Service", zap.Error(err))
-		}
-	} else {
-		h.logger.Info("Store deleted successfully")
+	requestid.Logger(ctx, h.logger).Info("Store deleted successfully")
+	h.sendSuccessReply(ctx, msg, "Store deleted successfully", nil)
 
-		err = sendSuccessResponseToGateway(h.gatewayUrl, "Store deleted successfully")
-		if err != nil {
-			h.logger.Error("Failed to send success response to Gateway Service", zap.Error(err))
-		}
-	}
+	return nil
 }
 
-func (h *MessageHandler) handleDeleteStoreVersion(msg amqp.Delivery, userLogin string) {
-	storeId := extractStoreID(msg)
-	versionId := extractVersionID(msg)
-
-	err := h.storeService.DeleteStoreVersion(storeId, versionId, userLogin)
+func (h *MessageHandler) handleDeleteStoreVersion(ctx context.Context, msg amqp.Delivery, envelope *Envelope) error {
+	err := h.storeService.DeleteStoreVersion(ctx, envelope.StoreID, envelope.VersionID, envelope.UserLogin)
 
 	if err != nil {
-		h.logger.Error("Failed to delete store version", zap.Error(err))
+		requestid.Logger(ctx, h.logger).Error("Failed to delete store version", zap.Error(err))
+		return h.sendServiceErrorReply(ctx, msg, err)
+	}
 
-		err = sendErrorResponseToGateway(h.gatewayUrl, err.Error())
-		if err != nil {
-			h.logger.Error("Failed to send error response to Gateway Service", zap.Error(err))
-		}
-	} else {
-		h.logger.Info("Store version deleted successfully")
+	requestid.Logger(ctx, h.logger).Info("Store version deleted successfully")
+	h.sendSuccessReply(ctx, msg, "Store version deleted successfully", nil)
 
-		err = sendSuccessResponseToGateway(h.gatewayUrl, "Store version deleted successfully")
-		if err != nil {
-			h.logger.Error("Failed to send success response to Gateway Service", zap.Error(err))
-		}
+	return nil
+}
+
+func (h *MessageHandler) handleUndeleteStore(ctx context.Context, msg amqp.Delivery, envelope *Envelope) error {
+	err := h.storeService.UndeleteStore(ctx, envelope.StoreID, envelope.UserLogin)
+
+	if err != nil {
+		requestid.Logger(ctx, h.logger).Error("Failed to undelete store", zap.Error(err))
+		return h.sendServiceErrorReply(ctx, msg, err)
 	}
+
+	requestid.Logger(ctx, h.logger).Info("Store undeleted successfully")
+	h.sendSuccessReply(ctx, msg, "Store undeleted successfully", nil)
+
+	return nil
 }
 
-func (h *MessageHandler) handleCreateStore(msg amqp.Delivery, userLogin string) {
-	storeData, err := extractStoreData(msg)
+func (h *MessageHandler) handleUndeleteStoreVersion(ctx context.Context, msg amqp.Delivery, envelope *Envelope) error {
+	err := h.storeService.UndeleteStoreVersion(ctx, envelope.StoreID, envelope.VersionID, envelope.UserLogin)
+
 	if err != nil {
-		h.logger.Error("Failed to extract data", zap.Error(err))
-		return
+		requestid.Logger(ctx, h.logger).Error("Failed to undelete store version", zap.Error(err))
+		return h.sendServiceErrorReply(ctx, msg, err)
 	}
 
+	requestid.Logger(ctx, h.logger).Info("Store version undeleted successfully")
+	h.sendSuccessReply(ctx, msg, "Store version undeleted successfully", nil)
+
+	return nil
+}
+
+func (h *MessageHandler) handleCreateStore(ctx context.Context, msg amqp.Delivery, envelope *Envelope) error {
+	storeData := envelope.StoreData()
+
 	srvStore := service.Store{
 		Name:        storeData.Name,
 		Address:     storeData.Address,
 		OwnerName:   storeData.OwnerName,
 		OpeningTime: storeData.OpeningTime,
 		ClosingTime: storeData.ClosingTime,
+
+		StructuredAddress: toStructuredAddress(storeData.StructuredAddress),
 	}
 
-	err = h.storeService.CreateStore(srvStore, userLogin)
+	store, err := h.storeService.CreateStore(ctx, srvStore, envelope.UserLogin, envelope.IdempotencyKey)
 	if err != nil {
-		h.logger.Error("Failed to create store", zap.Error(err))
+		requestid.Logger(ctx, h.logger).Error("Failed to create store", zap.Error(err))
+		return h.sendServiceErrorReply(ctx, msg, err)
+	}
 
-		err = sendErrorResponseToGateway(h.gatewayUrl, err.Error())
-		if err != nil {
-			h.logger.Error("Failed to send error response to Gateway Service", zap.Error(err))
-		}
-	} else {
-		h.logger.Info("Store created successfully")
+	requestid.Logger(ctx, h.logger).Info("Store created successfully")
+	h.sendSuccessReply(ctx, msg, "Store created successfully", toStoreReply(store))
 
-		err = sendSuccessResponseToGateway(h.gatewayUrl, "Store created successfully")
-		if err != nil {
-			h.logger.Error("Failed to send success response to Gateway Service", zap.Error(err))
-		}
-	}
+	return nil
 }
 
-func (h *MessageHandler) handleCreateStoreVersion(msg amqp.Delivery, login string) {
-	storeId := extractStoreID(msg)
-	storeVersionData, err := extractStoreVersionData(msg)
-	if err != nil {
-		h.logger.Error("Failed to extract data", zap.Error(err))
-		return
-	}
+func (h *MessageHandler) handleCreateStoreVersion(ctx context.Context, msg amqp.Delivery, envelope *Envelope) error {
+	storeVersionData := envelope.StoreVersionData()
 
 	srvStoreVersion := service.StoreVersion{
+		Name:        storeVersionData.Name,
+		Address:     storeVersionData.Address,
 		OwnerName:   storeVersionData.OwnerName,
 		OpeningTime: storeVersionData.OpeningTime,
 		ClosingTime: storeVersionData.ClosingTime,
+
+		StructuredAddress: toStructuredAddress(storeVersionData.StructuredAddress),
+		ExpectedRevisions: storeVersionData.ExpectedRevisions,
+		IfMatchAny:        storeVersionData.IfMatchAny,
 	}
 
-	err = h.storeService.CreateStoreVersion(srvStoreVersion, storeId, login)
+	storeVersion, err := h.storeService.CreateStoreVersion(ctx, srvStoreVersion, envelope.StoreID, envelope.UserLogin, envelope.IdempotencyKey)
 	if err != nil {
-		h.logger.Error("Failed to create store version", zap.Error(err))
+		requestid.Logger(ctx, h.logger).Error("Failed to create store version", zap.Error(err))
+		return h.sendServiceErrorReply(ctx, msg, err)
+	}
 
-		err = sendErrorResponseToGateway(h.gatewayUrl, err.Error())
-		if err != nil {
-			h.logger.Error("Failed to send error response to Gateway Service", zap.Error(err))
-		}
-	} else {
-		h.logger.Info("Store version created successfully")
+	requestid.Logger(ctx, h.logger).Info("Store version created successfully")
+	h.sendSuccessReply(ctx, msg, "Store version created successfully", toStoreVersionReply(storeVersion))
 
-		err = sendSuccessResponseToGateway(h.gatewayUrl, "Store version created successfully")
-		if err != nil {
-			h.logger.Error("Failed to send success response to Gateway Service", zap.Error(err))
-		}
-	}
+	return nil
 }
 
-func (h *MessageHandler) handleGetStore(msg amqp.Delivery) {
-	storeId := extractStoreID(msg)
-	store, err := h.storeService.GetStoreByID(storeId)
+func (h *MessageHandler) handleRestoreStoreVersion(ctx context.Context, msg amqp.Delivery, envelope *Envelope) error {
+	storeVersion, err := h.storeService.RestoreStoreVersion(ctx, envelope.StoreID, envelope.VersionID, envelope.UserLogin)
 	if err != nil {
-		h.logger.Error("Failed to get store", zap.Error(err))
+		requestid.Logger(ctx, h.logger).Error("Failed to restore store version", zap.Error(err))
+		return h.sendServiceErrorReply(ctx, msg, err)
+	}
 
-		err = sendErrorResponseToGateway(h.gatewayUrl, err.Error())
-		if err != nil {
-			h.logger.Error("Failed to send error response to Gateway Service", zap.Error(err))
-		}
-	} else {
-		h.logger.Info("Successfully got the store", zap.Any("store", store))
+	requestid.Logger(ctx, h.logger).Info("Store version restored successfully")
+	h.sendSuccessReply(ctx, msg, "Store version restored successfully", toStoreVersionReply(storeVersion))
 
-		err = sendSuccessResponseToGateway(h.gatewayUrl, store)
-		if err != nil {
-			h.logger.Error("Failed to send success response to Gateway Service", zap.Error(err))
-		}
-	}
+	return nil
 }
 
-func (h *MessageHandler) handleGetStoreHistory(msg amqp.Delivery) {
-	storeId := extractStoreID(msg)
-	storeHistory, err := h.storeService.GetStoreVersionHistory(storeId)
+func (h *MessageHandler) handleGetStore(ctx context.Context, msg amqp.Delivery, envelope *Envelope) error {
+	var store *model.Store
+	var err error
+	if at, ok := envelope.PointInTime(); ok {
+		store, err = h.storeService.GetStoreAt(ctx, envelope.StoreID, at)
+	} else {
+		store, err = h.storeService.GetStoreByID(ctx, envelope.StoreID)
+	}
 	if err != nil {
-		h.logger.Error("Failed to get store history", zap.Error(err))
+		requestid.Logger(ctx, h.logger).Error("Failed to get store", zap.Error(err))
+		return h.sendServiceErrorReply(ctx, msg, err)
+	}
 
-		err = sendErrorResponseToGateway(h.gatewayUrl, err.Error())
-		if err != nil {
-			h.logger.Error("Failed to send error response to Gateway Service", zap.Error(err))
-		}
-	} else {
-		h.logger.Info("Successfully got the version history", zap.Any("store", storeHistory))
+	requestid.Logger(ctx, h.logger).Info("Successfully got the store", zap.Any("store", store))
+	h.sendSuccessReply(ctx, msg, "", toStoreReply(store))
 
-		err = sendSuccessResponseToGateway(h.gatewayUrl, storeHistory)
-		if err != nil {
-			h.logger.Error("Failed to send success response to Gateway Service", zap.Error(err))
-		}
-	}
+	return nil
 }
 
-func (h *MessageHandler) handleGetStoreVersion(msg amqp.Delivery) {
-	storeId := extractStoreID(msg)
-	versionId := extractVersionID(msg)
-	storeVersion, err := h.storeService.GetStoreVersionByID(storeId, versionId)
+func (h *MessageHandler) handleGetStoreHistory(ctx context.Context, msg amqp.Delivery, envelope *Envelope) error {
+	page, err := h.storeService.GetStoreVersionHistoryPage(ctx, envelope.StoreID, envelope.HistoryQuery())
 	if err != nil {
-		h.logger.Error("Failed to get store version", zap.Error(err))
+		requestid.Logger(ctx, h.logger).Error("Failed to get store history", zap.Error(err))
+		return h.sendServiceErrorReply(ctx, msg, err)
+	}
 
-		err = sendErrorResponseToGateway(h.gatewayUrl, err.Error())
-		if err != nil {
-			h.logger.Error("Failed to send error response to Gateway Service", zap.Error(err))
-		}
-	} else {
-		h.logger.Info("Successfully got the store version", zap.Any("store", storeVersion))
+	requestid.Logger(ctx, h.logger).Info("Successfully got the version history", zap.Int("versions", len(page.Versions)))
+	h.sendSuccessReply(ctx, msg, "", contract.StoreVersionList{
+		Versions:   toStoreVersionsReply(page.Versions),
+		NextCursor: page.NextCursor,
+	})
+
+	return nil
+}
+
+func (h *MessageHandler) handleGetStoreDiff(ctx context.Context, msg amqp.Delivery, envelope *Envelope) error {
+	diffData := envelope.StoreDiffData()
 
-		err = sendSuccessResponseToGateway(h.gatewayUrl, storeVersion)
+	if diffData.Compact {
+		diffs, err := h.storeService.GetStoreHistoryDiff(ctx, envelope.StoreID)
 		if err != nil {
-			h.logger.Error("Failed to send success response to Gateway Service", zap.Error(err))
+			requestid.Logger(ctx, h.logger).Error("Failed to get store history diff", zap.Error(err))
+			return h.sendServiceErrorReply(ctx, msg, err)
 		}
+
+		requestid.Logger(ctx, h.logger).Info("Successfully got the store history diff", zap.Int("diffs", len(diffs)))
+		h.sendSuccessReply(ctx, msg, "", toStoreDiffsReply(envelope.StoreID, diffs))
+
+		return nil
 	}
-}
 
-func extractStoreID(msg amqp.Delivery) string {
-	var message Message
-	err := json.Unmarshal(msg.Body, &message)
+	diff, err := h.storeService.GetStoreDiff(ctx, envelope.StoreID, diffData.FromVersionID, diffData.ToVersionID)
 	if err != nil {
-		return ""
+		requestid.Logger(ctx, h.logger).Error("Failed to get store diff", zap.Error(err))
+		return h.sendServiceErrorReply(ctx, msg, err)
 	}
-	return message.StoreID
+
+	requestid.Logger(ctx, h.logger).Info("Successfully got the store diff")
+	h.sendSuccessReply(ctx, msg, "", toStoreDiffReply(envelope.StoreID, diff))
+
+	return nil
 }
 
-func extractVersionID(msg amqp.Delivery) string {
-	var message Message
-	err := json.Unmarshal(msg.Body, &message)
+func (h *MessageHandler) handleGetStoreVersion(ctx context.Context, msg amqp.Delivery, envelope *Envelope) error {
+	storeVersion, err := h.storeService.GetStoreVersionByID(ctx, envelope.StoreID, envelope.VersionID)
 	if err != nil {
-		// Обработка ошибки
-		return ""
+		requestid.Logger(ctx, h.logger).Error("Failed to get store version", zap.Error(err))
+		return h.sendServiceErrorReply(ctx, msg, err)
 	}
-	return message.VersionID
+
+	requestid.Logger(ctx, h.logger).Info("Successfully got the store version", zap.Any("store", storeVersion))
+	h.sendSuccessReply(ctx, msg, "", toStoreVersionReply(storeVersion))
+
+	return nil
 }
 
-func extractAction(msg amqp.Delivery) string {
-	var message Message
-	err := json.Unmarshal(msg.Body, &message)
+func (h *MessageHandler) handleListStores(ctx context.Context, msg amqp.Delivery, envelope *Envelope) error {
+	page, err := h.storeService.ListStores(ctx, envelope.StoreQuery())
 	if err != nil {
-		return ""
+		requestid.Logger(ctx, h.logger).Error("Failed to list stores", zap.Error(err))
+		return h.sendServiceErrorReply(ctx, msg, err)
 	}
-	return message.Action
+
+	requestid.Logger(ctx, h.logger).Info("Successfully listed stores", zap.Int("stores", len(page.Stores)))
+	h.sendSuccessReply(ctx, msg, "", toStoreListReply(page))
+
+	return nil
 }
 
-func extractStoreData(msg amqp.Delivery) (StoreFromMessage, error) {
-	var message Message
-	err := json.Unmarshal(msg.Body, &message)
+func (h *MessageHandler) handleListCities(ctx context.Context, msg amqp.Delivery, envelope *Envelope) error {
+	citiesData := envelope.ListCitiesData()
+
+	page, err := h.storeService.ListCities(ctx, citiesData.Limit, citiesData.Cursor)
 	if err != nil {
-		return StoreFromMessage{}, err
+		requestid.Logger(ctx, h.logger).Error("Failed to list cities", zap.Error(err))
+		return h.sendServiceErrorReply(ctx, msg, err)
 	}
 
-	var storeData StoreFromMessage
-	err = json.Unmarshal(message.Data, &storeData)
+	requestid.Logger(ctx, h.logger).Info("Successfully listed cities", zap.Int("cities", len(page.Cities)))
+	h.sendSuccessReply(ctx, msg, "", toCityListReply(page))
+
+	return nil
+}
+
+func (h *MessageHandler) handleSearchStores(ctx context.Context, msg amqp.Delivery, envelope *Envelope) error {
+	searchData := envelope.SearchStoresData()
+
+	results, err := h.storeService.SearchStores(ctx, searchData.Query, searchData.Limit)
 	if err != nil {
-		return StoreFromMessage{}, err
+		requestid.Logger(ctx, h.logger).Error("Failed to search stores", zap.Error(err))
+		return h.sendServiceErrorReply(ctx, msg, err)
+	}
+
+	requestid.Logger(ctx, h.logger).Info("Successfully searched stores", zap.Int("stores", len(results)))
+	h.sendSuccessReply(ctx, msg, "", toStoreSearchResultsReply(results))
+
+	return nil
+}
+
+// replyContentType answers in the content type of the request, JSON if it is missing or unsupported
+func replyContentType(msg amqp.Delivery) string {
+	if contract.IsSupportedContentType(msg.ContentType) {
+		return msg.ContentType
+	}
+	return contract.ContentTypeJSON
+}
+
+// extractRequestID reads the request id set by the gateway, falling back to the correlation id
+func extractRequestID(msg amqp.Delivery) string {
+	if requestID, ok := msg.Headers[requestid.Header].(string); ok && requestID != "" {
+		return requestID
+	}
+	return msg.CorrelationId
+}
+
+// errorCode maps service errors to the codes understood by the gateway
+func errorCode(err error) string {
+	switch {
+	case errors.Is(err, service.ErrStoreNotFound):
+		return contract.CodeStoreNotFound
+	case errors.Is(err, service.ErrVersionNotFound):
+		return contract.CodeVersionNotFound
+	case errors.Is(err, service.ErrPermissionDenied):
+		return contract.CodePermissionDenied
+	case errors.Is(err, service.ErrIdempotencyKeyConflict):
+		return contract.CodeIdempotencyConflict
+	case errors.Is(err, service.ErrOnlyVersion):
+		return contract.CodeOnlyVersion
+	case errors.Is(err, service.ErrPreconditionFailed):
+		return contract.CodePreconditionFailed
+	case errors.Is(err, service.ErrInvalidCursor):
+		return contract.CodeBadRequest
+	default:
+		return contract.CodeInternal
 	}
+}
+
+// sendServiceErrorReply replies with the service error. Internal errors are returned
+// without a reply, so the message is retried
+func (h *MessageHandler) sendServiceErrorReply(ctx context.Context, msg amqp.Delivery, err error) error {
+	code := errorCode(err)
+	if code == contract.CodeInternal {
+		return err
+	}
+
+	h.sendErrorReply(ctx, msg, code, err.Error())
+	return nil
+}
+
+// sendValidationErrorReply tells the gateway which fields of the message are wrong
+func (h *MessageHandler) sendValidationErrorReply(ctx context.Context, msg amqp.Delivery, err *ValidationError) {
+	h.sendReply(ctx, msg, contract.Reply{
+		Status:  contract.StatusError,
+		Code:    err.Code,
+		Message: err.Message,
+		Details: err.Fields,
+	})
+}
 
-	return storeData, nil
+func (h *MessageHandler) sendErrorReply(ctx context.Context, msg amqp.Delivery, code, errorMessage string) {
+	h.sendReply(ctx, msg, contract.Reply{
+		Status:  contract.StatusError,
+		Code:    code,
+		Message: errorMessage,
+	})
 }
 
-func extractStoreVersionData(msg amqp.Delivery) (StoreVersionFromMessage, error) {
-	var message Message
-	err := json.Unmarshal(msg.Body, &message)
+func (h *MessageHandler) sendSuccessReply(ctx context.Context, msg amqp.Delivery, successMessage string, data interface{}) {
+	h.sendReply(ctx, msg, contract.Reply{
+		Status:  contract.StatusSuccess,
+		Message: successMessage,
+		Payload: data,
+	})
+}
+
+func (h *MessageHandler) sendReply(ctx context.Context, msg amqp.Delivery, reply contract.Reply) {
+	if msg.ReplyTo == "" {
+		requestid.Logger(ctx, h.logger).Warn("Message has no reply_to property, reply is dropped",
+			zap.String("correlationId", msg.CorrelationId))
+		return
+	}
+
+	reply.RequestID = requestid.FromContext(ctx)
+
+	contentType := replyContentType(msg)
+
+	body, err := contract.EncodeReply(reply, contentType)
 	if err != nil {
-		return StoreVersionFromMessage{}, err
+		requestid.Logger(ctx, h.logger).Error("Failed to marshal reply", zap.Error(err))
+		return
 	}
 
-	var storeVersionData StoreVersionFromMessage
-	err = json.Unmarshal(message.Data, &storeVersionData)
+	err = h.publisher.Publish(
+		"",
+		msg.ReplyTo,
+		false,
+		false,
+		amqp.Publishing{
+			ContentType:   contentType,
+			CorrelationId: msg.CorrelationId,
+			Headers:       amqp.Table{requestid.Header: reply.RequestID},
+			Body:          body,
+		},
+	)
 	if err != nil {
-		return StoreVersionFromMessage{}, err
+		requestid.Logger(ctx, h.logger).Error("Failed to send reply to Gateway Service", zap.Error(err))
 	}
+}
 
-	return storeVersionData, nil
+func toStoreReply(store *model.Store) contract.Store {
+	return contract.Store{
+		StoreID:      store.StoreID,
+		Name:         store.Name,
+		Address:      store.Address,
+		CreatorLogin: store.CreatorLogin,
+		OwnerName:    store.OwnerName,
+		OpeningTime:  store.OpeningTime,
+		ClosingTime:  store.ClosingTime,
+		CreatedAt:    formatTime(store.CreatedAt),
+
+		StructuredAddress:   toAddressReply(store.StructuredAddress),
+		LatestVersionNumber: store.LatestVersionNumber,
+		Revision:            store.Revision,
+	}
 }
 
-func extractLogin(msg amqp.Delivery) string {
-	var message Message
-	err := json.Unmarshal(msg.Body, &message)
-	if err != nil {
-		return ""
+// formatTime writes creation times in UTC as RFC 3339 with fractions of a second,
+// so they can be passed back as the at, createdFrom and createdTo filters
+func formatTime(t time.Time) string {
+	return t.UTC().Format(time.RFC3339Nano)
+}
+
+// toStructuredAddress returns nil when the request has no structured address
+func toStructuredAddress(address *contract.Address) *model.StructuredAddress {
+	if address == nil {
+		return nil
+	}
+
+	return &model.StructuredAddress{
+		City:       strings.TrimSpace(address.City),
+		Street:     strings.TrimSpace(address.Street),
+		House:      strings.TrimSpace(address.House),
+		PostalCode: strings.TrimSpace(address.PostalCode),
+		Unit:       strings.TrimSpace(address.Unit),
+	}
+}
+
+func toAddressReply(address model.StructuredAddress) contract.Address {
+	return contract.Address{
+		City:       address.City,
+		Street:     address.Street,
+		House:      address.House,
+		PostalCode: address.PostalCode,
+		Unit:       address.Unit,
+	}
+}
+
+func toStoreListReply(page *service.StorePage) contract.StoreList {
+	stores := make([]contract.Store, 0, len(page.Stores))
+	for _, store := range page.Stores {
+		stores = append(stores, toStoreReply(store))
+	}
+	return contract.StoreList{Stores: stores, NextCursor: page.NextCursor}
+}
+
+func toCityListReply(page *service.CityPage) contract.CityList {
+	cities := make([]contract.City, 0, len(page.Cities))
+	for _, city := range page.Cities {
+		cities = append(cities, contract.City{Name: city.Name, Stores: city.Stores})
+	}
+	return contract.CityList{Cities: cities, NextCursor: page.NextCursor}
+}
+
+func toStoreSearchResultsReply(results []*model.StoreSearchResult) []contract.StoreSearchResult {
+	reply := make([]contract.StoreSearchResult, 0, len(results))
+	for _, result := range results {
+		reply = append(reply, contract.StoreSearchResult{
+			Store: toStoreReply(&result.Store),
+			Rank:  result.Rank,
+			Highlights: contract.StoreHighlights{
+				Name:      result.NameHighlight,
+				Address:   result.AddressHighlight,
+				OwnerName: result.OwnerNameHighlight,
+			},
+		})
+	}
+	return reply
+}
+
+func toStoreVersionReply(storeVersion *model.StoreVersion) contract.StoreVersion {
+	version := contract.StoreVersion{
+		VersionID:     storeVersion.VersionID,
+		StoreID:       storeVersion.StoreID,
+		VersionNumber: storeVersion.VersionNumber,
+		CreatorLogin:  storeVersion.CreatorLogin,
+		Name:          storeVersion.Name,
+		Address:       storeVersion.Address,
+		OwnerName:     storeVersion.OwnerName,
+		OpeningTime:   storeVersion.OpeningTime,
+		ClosingTime:   storeVersion.ClosingTime,
+		CreatedAt:     formatTime(storeVersion.CreatedAt),
+		IsLast:        storeVersion.IsLast,
+
+		StructuredAddress: toAddressReply(storeVersion.StructuredAddress),
+		StoreRevision:     storeVersion.StoreRevision,
+	}
+
+	if storeVersion.RestoredFromVersionID != nil {
+		version.RestoredFromVersionID = *storeVersion.RestoredFromVersionID
+	}
+
+	return version
+}
+
+func toStoreVersionsReply(storeVersions []*model.StoreVersion) []contract.StoreVersion {
+	versions := make([]contract.StoreVersion, 0, len(storeVersions))
+	for _, storeVersion := range storeVersions {
+		versions = append(versions, toStoreVersionReply(storeVersion))
+	}
+	return versions
+}
+
+func toStoreDiffReply(storeID string, diff *service.StoreDiff) contract.StoreDiff {
+	changes := make([]contract.FieldChange, 0, len(diff.Changes))
+	for _, change := range diff.Changes {
+		changes = append(changes, contract.FieldChange{
+			Field:   change.Field,
+			Before:  change.Before,
+			After:   change.After,
+			Changed: change.Changed,
+		})
+	}
+
+	return contract.StoreDiff{
+		StoreID: storeID,
+		From:    toStoreVersionRef(diff.From),
+		To:      toStoreVersionRef(diff.To),
+		Changes: changes,
 	}
-	return message.UserLogin
 }
 
-func sendErrorResponseToGateway(url string, errorMessage interface{}) error {
-	errorPayload := map[string]interface{}{
-		"error": errorMessage,
+func toStoreDiffsReply(storeID string, diffs []*service.StoreDiff) []contract.StoreDiff {
+	reply := make([]contract.StoreDiff, 0, len(diffs))
+	for _, diff := range diffs {
+		reply = append(reply, toStoreDiffReply(storeID, diff))
 	}
-	return sendResponseToGateway(url, errorPayload)
+	return reply
 }
 
-func sendSuccessResponseToGateway(url string, successMessage interface{}) error {
-	successPayload := map[string]interface{}{
-		"message": successMessage,
+func toStoreVersionRef(storeVersion *model.StoreVersion) contract.StoreVersionRef {
+	return contract.StoreVersionRef{
+		VersionID:     storeVersion.VersionID,
+		VersionNumber: storeVersion.VersionNumber,
+		CreatorLogin:  storeVersion.CreatorLogin,
+		CreatedAt:     formatTime(storeVersion.CreatedAt),
 	}
-	return sendResponseToGateway(url, successPayload)
 }
@@ -0,0 +1,18 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS idempotency_keys (
+login VARCHAR(255) NOT NULL,
+idempotency_key VARCHAR(255) NOT NULL,
+action VARCHAR(64) NOT NULL,
+-- request_hash tells a retry from a key reused for another request
+request_hash VARCHAR(64) NOT NULL,
+response JSONB NOT NULL,
+created_at TIMESTAMP NOT NULL DEFAULT now(),
+PRIMARY KEY (login, idempotency_key)
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS idempotency_keys;
+-- +goose StatementEnd
@@ -0,0 +1,20 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS outbox (
+id BIGSERIAL PRIMARY KEY,
+store_id VARCHAR(255) NOT NULL,
+event_type VARCHAR(64) NOT NULL,
+payload JSONB NOT NULL,
+created_at TIMESTAMP NOT NULL DEFAULT now(),
+published_at TIMESTAMP,
+-- a relay claims events until claimed_until and publishes them outside of a transaction
+claimed_until TIMESTAMP
+);
+CREATE INDEX IF NOT EXISTS outbox_unpublished_idx ON outbox (id) WHERE published_at IS NULL;
+CREATE INDEX IF NOT EXISTS outbox_published_idx ON outbox (published_at) WHERE published_at IS NOT NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS outbox;
+-- +goose StatementEnd
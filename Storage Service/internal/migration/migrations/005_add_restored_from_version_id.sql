@@ -0,0 +1,9 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE store_versions ADD COLUMN IF NOT EXISTS restored_from_version_id INT;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE store_versions DROP COLUMN IF EXISTS restored_from_version_id;
+-- +goose StatementEnd
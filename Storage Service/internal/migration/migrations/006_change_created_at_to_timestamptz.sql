@@ -0,0 +1,13 @@
+-- +goose Up
+-- +goose StatementBegin
+-- creation times were written without a zone in the local time of the storage service, they are read
+-- in the time zone of the database session. Both are UTC in the Docker Compose setup
+ALTER TABLE stores ALTER COLUMN created_at TYPE TIMESTAMPTZ USING created_at::timestamptz;
+ALTER TABLE store_versions ALTER COLUMN created_at TYPE TIMESTAMPTZ USING created_at::timestamptz;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE store_versions ALTER COLUMN created_at TYPE VARCHAR(255) USING to_char(created_at, 'YYYY-MM-DD HH24:MI:SS');
+ALTER TABLE stores ALTER COLUMN created_at TYPE VARCHAR(255) USING to_char(created_at, 'YYYY-MM-DD HH24:MI:SS');
+-- +goose StatementEnd
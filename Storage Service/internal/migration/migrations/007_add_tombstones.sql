@@ -0,0 +1,28 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE stores ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP;
+ALTER TABLE stores ADD COLUMN IF NOT EXISTS deleted_by VARCHAR(255);
+ALTER TABLE store_versions ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP;
+ALTER TABLE store_versions ADD COLUMN IF NOT EXISTS deleted_by VARCHAR(255);
+CREATE INDEX IF NOT EXISTS stores_deleted_at_idx ON stores (deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE INDEX IF NOT EXISTS store_versions_deleted_at_idx ON store_versions (deleted_at) WHERE deleted_at IS NOT NULL;
+
+-- the highest version number ever given to the store, it doesn't go down when versions are purged
+ALTER TABLE stores ADD COLUMN IF NOT EXISTS last_version_number INT NOT NULL DEFAULT 0;
+
+UPDATE stores AS s
+SET last_version_number = v.max_number
+FROM (SELECT store_id, MAX(version_number) AS max_number FROM store_versions GROUP BY store_id) AS v
+WHERE v.store_id = s.store_id;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS store_versions_deleted_at_idx;
+DROP INDEX IF EXISTS stores_deleted_at_idx;
+ALTER TABLE stores DROP COLUMN IF EXISTS last_version_number;
+ALTER TABLE store_versions DROP COLUMN IF EXISTS deleted_by;
+ALTER TABLE store_versions DROP COLUMN IF EXISTS deleted_at;
+ALTER TABLE stores DROP COLUMN IF EXISTS deleted_by;
+ALTER TABLE stores DROP COLUMN IF EXISTS deleted_at;
+-- +goose StatementEnd
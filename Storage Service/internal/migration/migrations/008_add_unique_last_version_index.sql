@@ -0,0 +1,17 @@
+-- +goose Up
+-- +goose StatementBegin
+UPDATE store_versions SET is_last = false WHERE is_last = true;
+UPDATE store_versions SET is_last = true
+WHERE version_id IN (
+    SELECT DISTINCT ON (store_id) version_id
+    FROM store_versions
+    WHERE deleted_at IS NULL
+    ORDER BY store_id, version_number DESC, version_id DESC
+);
+CREATE UNIQUE INDEX IF NOT EXISTS store_versions_last_idx ON store_versions (store_id) WHERE is_last;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS store_versions_last_idx;
+-- +goose StatementEnd
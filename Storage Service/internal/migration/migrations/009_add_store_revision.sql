@@ -0,0 +1,10 @@
+-- +goose Up
+-- +goose StatementBegin
+-- revision grows with every change of the store or its versions, ETags are derived from it
+ALTER TABLE stores ADD COLUMN IF NOT EXISTS revision BIGINT NOT NULL DEFAULT 1;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE stores DROP COLUMN IF EXISTS revision;
+-- +goose StatementEnd
@@ -0,0 +1,17 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE store_versions ADD COLUMN IF NOT EXISTS name VARCHAR(255);
+ALTER TABLE store_versions ADD COLUMN IF NOT EXISTS address VARCHAR(255);
+UPDATE store_versions AS v
+SET name = s.name, address = s.address
+FROM stores AS s
+WHERE v.store_id = s.store_id AND v.name IS NULL;
+ALTER TABLE store_versions ALTER COLUMN name SET NOT NULL;
+ALTER TABLE store_versions ALTER COLUMN address SET NOT NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE store_versions DROP COLUMN IF EXISTS address;
+ALTER TABLE store_versions DROP COLUMN IF EXISTS name;
+-- +goose StatementEnd
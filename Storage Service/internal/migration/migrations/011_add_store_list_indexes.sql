@@ -0,0 +1,16 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE INDEX IF NOT EXISTS stores_creator_login_idx ON stores (creator_login) WHERE deleted_at IS NULL;
+CREATE INDEX IF NOT EXISTS stores_created_at_idx ON stores (created_at, store_id) WHERE deleted_at IS NULL;
+CREATE INDEX IF NOT EXISTS store_versions_last_name_idx ON store_versions (name, store_id) WHERE is_last;
+-- text_pattern_ops lets prefix filters use the index whatever the collation is
+CREATE INDEX IF NOT EXISTS store_versions_last_name_prefix_idx ON store_versions (lower(name) text_pattern_ops) WHERE is_last;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS store_versions_last_name_prefix_idx;
+DROP INDEX IF EXISTS store_versions_last_name_idx;
+DROP INDEX IF EXISTS stores_created_at_idx;
+DROP INDEX IF EXISTS stores_creator_login_idx;
+-- +goose StatementEnd
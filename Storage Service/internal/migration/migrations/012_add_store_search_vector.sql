@@ -0,0 +1,24 @@
+-- +goose Up
+-- +goose StatementBegin
+-- store_search matches whole words like 'simple' but drops stop words such as "on" and "that"
+CREATE TEXT SEARCH DICTIONARY store_search_simple (TEMPLATE = pg_catalog.simple, STOPWORDS = english);
+CREATE TEXT SEARCH CONFIGURATION store_search (COPY = pg_catalog.simple);
+ALTER TEXT SEARCH CONFIGURATION store_search ALTER MAPPING REPLACE simple WITH store_search_simple;
+
+ALTER TABLE store_versions ADD COLUMN IF NOT EXISTS search_vector tsvector
+    GENERATED ALWAYS AS (
+        setweight(to_tsvector('store_search'::regconfig, name), 'A') ||
+        setweight(to_tsvector('store_search'::regconfig, address), 'B') ||
+        setweight(to_tsvector('store_search'::regconfig, owner_name), 'C')
+    ) STORED;
+CREATE INDEX IF NOT EXISTS store_versions_search_idx ON store_versions USING GIN (search_vector) WHERE is_last;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS store_versions_search_idx;
+ALTER TABLE store_versions DROP COLUMN IF EXISTS search_vector;
+
+DROP TEXT SEARCH CONFIGURATION IF EXISTS store_search;
+DROP TEXT SEARCH DICTIONARY IF EXISTS store_search_simple;
+-- +goose StatementEnd
@@ -0,0 +1,38 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE stores ADD COLUMN IF NOT EXISTS city VARCHAR(255) NOT NULL DEFAULT '';
+ALTER TABLE stores ADD COLUMN IF NOT EXISTS street VARCHAR(255) NOT NULL DEFAULT '';
+ALTER TABLE stores ADD COLUMN IF NOT EXISTS house VARCHAR(255) NOT NULL DEFAULT '';
+ALTER TABLE stores ADD COLUMN IF NOT EXISTS postal_code VARCHAR(32) NOT NULL DEFAULT '';
+ALTER TABLE stores ADD COLUMN IF NOT EXISTS unit VARCHAR(32) NOT NULL DEFAULT '';
+UPDATE stores SET
+    city = trim(split_part(address, ',', 1)),
+    street = trim(split_part(address, ',', 2)),
+    house = CASE WHEN address ~ '^[^,]*,[^,]*,' THEN trim(regexp_replace(address, '^[^,]*,[^,]*,', '')) ELSE '' END;
+
+ALTER TABLE store_versions ADD COLUMN IF NOT EXISTS city VARCHAR(255) NOT NULL DEFAULT '';
+ALTER TABLE store_versions ADD COLUMN IF NOT EXISTS street VARCHAR(255) NOT NULL DEFAULT '';
+ALTER TABLE store_versions ADD COLUMN IF NOT EXISTS house VARCHAR(255) NOT NULL DEFAULT '';
+ALTER TABLE store_versions ADD COLUMN IF NOT EXISTS postal_code VARCHAR(32) NOT NULL DEFAULT '';
+ALTER TABLE store_versions ADD COLUMN IF NOT EXISTS unit VARCHAR(32) NOT NULL DEFAULT '';
+UPDATE store_versions SET
+    city = trim(split_part(address, ',', 1)),
+    street = trim(split_part(address, ',', 2)),
+    house = CASE WHEN address ~ '^[^,]*,[^,]*,' THEN trim(regexp_replace(address, '^[^,]*,[^,]*,', '')) ELSE '' END;
+CREATE INDEX IF NOT EXISTS store_versions_last_city_idx ON store_versions (lower(city), store_id) WHERE is_last;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS store_versions_last_city_idx;
+ALTER TABLE store_versions DROP COLUMN IF EXISTS unit;
+ALTER TABLE store_versions DROP COLUMN IF EXISTS postal_code;
+ALTER TABLE store_versions DROP COLUMN IF EXISTS house;
+ALTER TABLE store_versions DROP COLUMN IF EXISTS street;
+ALTER TABLE store_versions DROP COLUMN IF EXISTS city;
+ALTER TABLE stores DROP COLUMN IF EXISTS unit;
+ALTER TABLE stores DROP COLUMN IF EXISTS postal_code;
+ALTER TABLE stores DROP COLUMN IF EXISTS house;
+ALTER TABLE stores DROP COLUMN IF EXISTS street;
+ALTER TABLE stores DROP COLUMN IF EXISTS city;
+-- +goose StatementEnd
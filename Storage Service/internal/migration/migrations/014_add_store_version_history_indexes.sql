@@ -0,0 +1,13 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE INDEX IF NOT EXISTS store_versions_history_idx ON store_versions (store_id, version_number) WHERE deleted_at IS NULL;
+CREATE INDEX IF NOT EXISTS store_versions_creator_history_idx ON store_versions (store_id, creator_login, version_number) WHERE deleted_at IS NULL;
+CREATE INDEX IF NOT EXISTS store_versions_created_at_idx ON store_versions (store_id, created_at) WHERE deleted_at IS NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS store_versions_created_at_idx;
+DROP INDEX IF EXISTS store_versions_creator_history_idx;
+DROP INDEX IF EXISTS store_versions_history_idx;
+-- +goose StatementEnd
@@ -0,0 +1,9 @@
+package model
+
+// City is a city with the number of live stores whose last version is in it.
+// Key is the lowercased name cities are grouped and ordered by
+type City struct {
+	Name   string `db:"name"`
+	Key    string `db:"city_key"`
+	Stores int    `db:"stores"`
+}
@@ -0,0 +1,22 @@
+package model
+
+import "errors"
+
+// ErrIdempotencyKeyConflict is returned when a key is sent again with another action or request
+var ErrIdempotencyKeyConflict = errors.New("idempotency key was already used for another request")
+
+// IdempotencyKey remembers the outcome of a write, so a retry with the same key gets it back
+type IdempotencyKey struct {
+	Login       string `db:"login"`
+	Key         string `db:"idempotency_key"`
+	Action      string `db:"action"`
+	RequestHash string `db:"request_hash"`
+	Response    []byte `db:"response"`
+}
+
+// IdempotentRequest is a write sent with an idempotency key. RequestHash identifies the request,
+// a retry with the same key must send the same request
+type IdempotentRequest struct {
+	Key         string
+	RequestHash string
+}
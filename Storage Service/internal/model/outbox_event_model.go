@@ -0,0 +1,24 @@
+package model
+
+import "time"
+
+// Store domain events, the event type is also the routing key they are published with
+const (
+	EventStoreCreated          = "store.created"
+	EventStoreVersionCreated   = "store.version.created"
+	EventStoreDeleted          = "store.deleted"
+	EventStoreVersionDeleted   = "store.version.deleted"
+	EventStoreVersionRestored  = "store.version.restored"
+	EventStoreUndeleted        = "store.undeleted"
+	EventStoreVersionUndeleted = "store.version.undeleted"
+)
+
+// OutboxEvent is a domain event saved in the transaction of the change it describes.
+// The relay publishes it later, so the event is never lost or sent for a rolled back change
+type OutboxEvent struct {
+	ID        int64     `db:"id"`
+	StoreID   string    `db:"store_id"`
+	Type      string    `db:"event_type"`
+	Payload   []byte    `db:"payload"`
+	CreatedAt time.Time `db:"created_at"`
+}
@@ -0,0 +1,41 @@
+package model
+
+import "time"
+
+// Columns stores can be listed by
+const (
+	StoreSortName      = "name"
+	StoreSortCreatedAt = "created_at"
+)
+
+// StoreFilter selects a page of stores, zero fields don't filter. CreatedFrom and CreatedTo are inclusive.
+// AfterValue and AfterID are the sort value and id of the last store
+// of the previous page, AfterID is 0 on the first page
+type StoreFilter struct {
+	CreatorLogin string
+	NamePrefix   string
+	City         string
+	CreatedFrom  time.Time
+	CreatedTo    time.Time
+
+	SortBy     string
+	Descending bool
+	Limit      int
+
+	AfterValue string
+	AfterID    int
+}
+
+// StoreVersionFilter selects a page of the versions of a store, zero fields don't filter. CreatedFrom
+// and CreatedTo are inclusive. Versions are ordered by number,
+// AfterNumber is the number of the last version of the previous page, 0 on the first page
+type StoreVersionFilter struct {
+	CreatorLogin string
+	CreatedFrom  time.Time
+	CreatedTo    time.Time
+
+	Descending bool
+	Limit      int
+
+	AfterNumber int
+}
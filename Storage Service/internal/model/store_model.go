@@ -1,12 +1,31 @@
 package model
 
+import "time"
+
+// StructuredAddress is the address split into parts, the Address string of stores and versions
+// is "city, street, house". PostalCode and Unit are optional
+type StructuredAddress struct {
+	City       string `db:"city" json:"city"`
+	Street     string `db:"street" json:"street"`
+	House      string `db:"house" json:"house"`
+	PostalCode string `db:"postal_code" json:"postalCode,omitempty"`
+	Unit       string `db:"unit" json:"unit,omitempty"`
+}
+
+// Store is a store as it was created. LatestVersionNumber is the number of its last version, it is only
+// filled in by reads. Revision grows with every change of the store or its versions
 type Store struct {
-	StoreID      int    `db:"store_id"`
-	Name         string `db:"name" binding:"required"`
-	Address      string `db:"address" binding:"required"`
-	CreatorLogin string `db:"creator_login" binding:"required"`
-	OwnerName    string `db:"owner_name" binding:"required"`
-	OpeningTime  string `db:"opening_time" binding:"required"`
-	ClosingTime  string `db:"closing_time" binding:"required"`
-	CreatedAt    string `db:"created_at" binding:"required"`
+	StoreID      int       `db:"store_id" json:"storeId"`
+	Name         string    `db:"name" json:"name" binding:"required"`
+	Address      string    `db:"address" json:"address" binding:"required"`
+	CreatorLogin string    `db:"creator_login" json:"creatorLogin" binding:"required"`
+	OwnerName    string    `db:"owner_name" json:"ownerName" binding:"required"`
+	OpeningTime  string    `db:"opening_time" json:"openingTime" binding:"required"`
+	ClosingTime  string    `db:"closing_time" json:"closingTime" binding:"required"`
+	CreatedAt    time.Time `db:"created_at" json:"createdAt" binding:"required"`
+
+	StructuredAddress
+
+	LatestVersionNumber int   `db:"latest_version_number" json:"latestVersionNumber"`
+	Revision            int64 `db:"revision" json:"revision"`
 }
@@ -0,0 +1,12 @@
+package model
+
+// StoreSearchResult is a store found by a full-text search with the fields of its last version.
+// Rank is higher for better matches, highlights are the fields with matching words wrapped in <b></b>
+type StoreSearchResult struct {
+	Store
+
+	Rank               float64 `db:"rank"`
+	NameHighlight      string  `db:"name_highlight"`
+	AddressHighlight   string  `db:"address_highlight"`
+	OwnerNameHighlight string  `db:"owner_name_highlight"`
+}
@@ -1,13 +1,47 @@
 package model
 
+import (
+	"errors"
+	"time"
+)
+
+var (
+	// ErrOnlyVersion is returned when the last remaining version of a store is deleted, the store must be deleted instead
+	ErrOnlyVersion = errors.New("the only version of a store can't be deleted, delete the store instead")
+	// ErrVersionMismatch is returned when a version is added to a store that has changed since the client read it
+	ErrVersionMismatch = errors.New("the store has changed")
+)
+
+// StorePrecondition is checked before a version is added to a store, the zero value checks nothing.
+// Revisions are the store revisions the client expects, Exists only requires a live store
+type StorePrecondition struct {
+	Revisions []int64
+	Exists    bool
+}
+
+// IsSet tells whether the precondition checks anything
+func (p StorePrecondition) IsSet() bool {
+	return p.Exists || len(p.Revisions) > 0
+}
+
+// StoreVersion is a snapshot of the store name, address, owner and hours.
+// RestoredFromVersionID is the version a restore copied, nil for versions created directly.
+// StoreRevision is the store revision right after the version was inserted, it is not stored with the version
 type StoreVersion struct {
-	VersionID     int    `db:"version_id"`
-	StoreID       string `db:"store_id"`
-	VersionNumber int    `db:"version_number" binding:"required"`
-	CreatorLogin  string `db:"creator_login" binding:"required"`
-	OwnerName     string `db:"owner_name" binding:"required"`
-	OpeningTime   string `db:"opening_time" binding:"required"`
-	ClosingTime   string `db:"closing_time" binding:"required"`
-	CreatedAt     string `db:"created_at" binding:"required"`
-	IsLast        bool   `db:"is_last" binding:"required"`
+	VersionID     int       `db:"version_id" json:"versionId"`
+	StoreID       string    `db:"store_id" json:"storeId"`
+	VersionNumber int       `db:"version_number" json:"versionNumber" binding:"required"`
+	CreatorLogin  string    `db:"creator_login" json:"creatorLogin" binding:"required"`
+	Name          string    `db:"name" json:"name" binding:"required"`
+	Address       string    `db:"address" json:"address" binding:"required"`
+	OwnerName     string    `db:"owner_name" json:"ownerName" binding:"required"`
+	OpeningTime   string    `db:"opening_time" json:"openingTime" binding:"required"`
+	ClosingTime   string    `db:"closing_time" json:"closingTime" binding:"required"`
+	CreatedAt     time.Time `db:"created_at" json:"createdAt" binding:"required"`
+	IsLast        bool      `db:"is_last" json:"isLast" binding:"required"`
+
+	StructuredAddress
+
+	RestoredFromVersionID *int  `db:"restored_from_version_id" json:"restoredFromVersionId,omitempty"`
+	StoreRevision         int64 `db:"-" json:"storeRevision,omitempty"`
 }
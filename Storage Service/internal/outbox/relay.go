@@ -0,0 +1,265 @@
+package outbox
+
+import (
+	"StorageService/internal/config"
+	"StorageService/internal/model"
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/streadway/amqp"
+	"go.uber.org/zap"
+	"strconv"
+	"sync"
+	"time"
+)
+
+var (
+	ErrNotConnected = errors.New("relay channel is not open")
+	ErrNotConfirmed = errors.New("broker did not confirm the event")
+)
+
+type Repository interface {
+	ClaimOutboxEvents(ctx context.Context, limit int, lease time.Duration) ([]model.OutboxEvent, error)
+	SettleOutboxEvents(ctx context.Context, published, released []int64) error
+	DeletePublishedOutboxEvents(ctx context.Context, olderThan time.Duration) (int64, error)
+}
+
+// Event is the body of a published domain event
+type Event struct {
+	ID         int64           `json:"eventId"`
+	Type       string          `json:"type"`
+	StoreID    string          `json:"storeId"`
+	OccurredAt time.Time       `json:"occurredAt"`
+	Data       json.RawMessage `json:"data"`
+}
+
+// Relay publishes events saved in the outbox table. It claims a batch, publishes it without holding
+// row locks and then marks the published events. An event is marked as published only after the broker
+// confirms it, so delivery is at least once and consumers must tolerate duplicates
+type Relay struct {
+	repository Repository
+	cfg        *config.OutboxConfig
+	logger     *zap.Logger
+
+	mu       sync.Mutex
+	conn     *amqp.Connection
+	channel  *amqp.Channel
+	confirms chan amqp.Confirmation
+}
+
+func NewRelay(repository Repository, cfg *config.OutboxConfig, logger *zap.Logger) *Relay {
+	return &Relay{
+		repository: repository,
+		cfg:        cfg,
+		logger:     logger.With(zap.String("place", "OutboxRelay")),
+	}
+}
+
+// Setup declares the events exchange and opens a channel in confirm mode. It runs again on every reconnect
+func (r *Relay) Setup(conn *amqp.Connection, _ *amqp.Channel) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.conn = conn
+
+	return r.openChannel()
+}
+
+func (r *Relay) openChannel() error {
+	channel, err := r.conn.Channel()
+	if err != nil {
+		return err
+	}
+
+	err = channel.ExchangeDeclare(
+		r.cfg.Exchange.Name,    // name
+		r.cfg.Exchange.Kind,    // type
+		r.cfg.Exchange.Durable, // durable
+		false,                  // auto-deleted
+		false,                  // internal
+		false,                  // no-wait
+		nil,                    // arguments
+	)
+	if err != nil {
+		return err
+	}
+
+	if err = channel.Confirm(false); err != nil {
+		return err
+	}
+
+	r.channel = channel
+	r.confirms = channel.NotifyPublish(make(chan amqp.Confirmation, 1))
+
+	return nil
+}
+
+// resetChannel drops a channel whose confirms can't be trusted anymore, the next publish opens a new one
+func (r *Relay) resetChannel() {
+	_ = r.channel.Close()
+	r.channel = nil
+}
+
+// Run polls the outbox and removes old published events until ctx is cancelled
+func (r *Relay) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.cfg.PollInterval)
+	defer ticker.Stop()
+
+	var cleanup <-chan time.Time
+	if r.cfg.Retention > 0 && r.cfg.CleanupInterval > 0 {
+		cleanupTicker := time.NewTicker(r.cfg.CleanupInterval)
+		defer cleanupTicker.Stop()
+		cleanup = cleanupTicker.C
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			r.logger.Info("Outbox relay stopped")
+			return
+		case <-ticker.C:
+			r.drain(ctx)
+		case <-cleanup:
+			r.cleanup(ctx)
+		}
+	}
+}
+
+// drain publishes batches until the outbox is empty or publishing fails
+func (r *Relay) drain(ctx context.Context) {
+	for ctx.Err() == nil {
+		events, err := r.repository.ClaimOutboxEvents(ctx, r.cfg.BatchSize, r.lease())
+		if err != nil {
+			if !errors.Is(err, ctx.Err()) {
+				r.logger.With(zap.Error(err)).Error("Failed to claim outbox events")
+			}
+			return
+		}
+
+		published, publishErr := r.publishAll(ctx, events)
+		if len(published) > 0 {
+			r.logger.Debug("Outbox events published", zap.Int("count", len(published)))
+		}
+
+		if err = r.settle(ctx, events, published); err != nil {
+			r.logger.With(zap.Error(err)).Error("Failed to mark outbox events as published")
+			return
+		}
+		if publishErr != nil || len(events) < r.cfg.BatchSize {
+			return
+		}
+	}
+}
+
+// publishAll publishes events one by one and stops at the first failure or when ctx is cancelled,
+// so events of a store never overtake each other
+func (r *Relay) publishAll(ctx context.Context, events []model.OutboxEvent) ([]int64, error) {
+	published := make([]int64, 0, len(events))
+
+	for _, event := range events {
+		if err := ctx.Err(); err != nil {
+			return published, err
+		}
+		if err := r.publish(event); err != nil {
+			r.logger.Error("Failed to publish outbox event", zap.Int64("eventId", event.ID), zap.Error(err))
+			return published, err
+		}
+		published = append(published, event.ID)
+	}
+
+	return published, nil
+}
+
+// settle marks the published events and releases the rest of the batch. It runs on shutdown too,
+// otherwise the released events would wait for the lease to expire
+func (r *Relay) settle(ctx context.Context, events []model.OutboxEvent, published []int64) error {
+	released := make([]int64, 0, len(events)-len(published))
+	for _, event := range events[len(published):] {
+		released = append(released, event.ID)
+	}
+
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), r.cfg.ConfirmTimeout)
+	defer cancel()
+
+	return r.repository.SettleOutboxEvents(ctx, published, released)
+}
+
+// lease is how long a batch stays claimed. Every event waits for its confirm at most ConfirmTimeout,
+// so the lease outlasts the batch unless the relay dies
+func (r *Relay) lease() time.Duration {
+	return time.Duration(r.cfg.BatchSize+1) * r.cfg.ConfirmTimeout
+}
+
+// cleanup removes events published more than the retention period ago
+func (r *Relay) cleanup(ctx context.Context) {
+	deleted, err := r.repository.DeletePublishedOutboxEvents(ctx, r.cfg.Retention)
+	if err != nil {
+		if ctx.Err() == nil {
+			r.logger.With(zap.Error(err)).Error("Failed to remove published outbox events")
+		}
+		return
+	}
+
+	if deleted > 0 {
+		r.logger.Info("Removed published outbox events", zap.Int64("count", deleted))
+	}
+}
+
+// publish sends one event and waits for the broker to confirm it.
+// Events are sent one by one, so events of the same store keep their order
+func (r *Relay) publish(event model.OutboxEvent) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.conn == nil {
+		return ErrNotConnected
+	}
+	if r.channel == nil {
+		if err := r.openChannel(); err != nil {
+			return err
+		}
+	}
+
+	body, err := json.Marshal(Event{
+		ID:         event.ID,
+		Type:       event.Type,
+		StoreID:    event.StoreID,
+		OccurredAt: event.CreatedAt,
+		Data:       event.Payload,
+	})
+	if err != nil {
+		return err
+	}
+
+	err = r.channel.Publish(
+		r.cfg.Exchange.Name,
+		event.Type,
+		false,
+		false,
+		amqp.Publishing{
+			ContentType:  "application/json",
+			DeliveryMode: amqp.Persistent,
+			MessageId:    strconv.FormatInt(event.ID, 10),
+			Type:         event.Type,
+			Timestamp:    event.CreatedAt,
+			Body:         body,
+		},
+	)
+	if err != nil {
+		r.resetChannel()
+		return err
+	}
+
+	select {
+	case confirm, ok := <-r.confirms:
+		if !ok || !confirm.Ack {
+			r.resetChannel()
+			return ErrNotConfirmed
+		}
+		return nil
+	case <-time.After(r.cfg.ConfirmTimeout):
+		// a late confirm would be taken for the next event, so the channel is not reused
+		r.resetChannel()
+		return ErrNotConfirmed
+	}
+}
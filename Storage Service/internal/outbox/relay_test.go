@@ -0,0 +1,83 @@
+package outbox
+
+import (
+	"StorageService/internal/config"
+	"StorageService/internal/model"
+	"context"
+	"go.uber.org/zap"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type fakeRepository struct {
+	batches   [][]model.OutboxEvent
+	lease     time.Duration
+	published []int64
+	released  []int64
+}
+
+func (f *fakeRepository) ClaimOutboxEvents(_ context.Context, _ int, lease time.Duration) ([]model.OutboxEvent, error) {
+	f.lease = lease
+	if len(f.batches) == 0 {
+		return nil, nil
+	}
+
+	events := f.batches[0]
+	f.batches = f.batches[1:]
+	return events, nil
+}
+
+func (f *fakeRepository) SettleOutboxEvents(ctx context.Context, published, released []int64) error {
+	f.published = append(f.published, published...)
+	f.released = append(f.released, released...)
+	return ctx.Err()
+}
+
+func (f *fakeRepository) DeletePublishedOutboxEvents(context.Context, time.Duration) (int64, error) {
+	return 0, nil
+}
+
+func newTestRelay(repository Repository) *Relay {
+	return NewRelay(repository, &config.OutboxConfig{BatchSize: 2, ConfirmTimeout: time.Second}, zap.NewNop())
+}
+
+func TestDrainReleasesUnpublishedEvents(t *testing.T) {
+	repository := &fakeRepository{batches: [][]model.OutboxEvent{{{ID: 1}, {ID: 2}}, {{ID: 3}}}}
+
+	// the relay is not connected, so the first event fails and the batch goes back to the outbox
+	newTestRelay(repository).drain(context.Background())
+
+	if len(repository.published) != 0 {
+		t.Errorf("published %v, want none", repository.published)
+	}
+	if !reflect.DeepEqual(repository.released, []int64{1, 2}) {
+		t.Errorf("released %v, want [1 2]", repository.released)
+	}
+	if len(repository.batches) != 1 {
+		t.Error("the relay claimed another batch after a failure")
+	}
+	if repository.lease != 3*time.Second {
+		t.Errorf("lease %v, want 3s", repository.lease)
+	}
+}
+
+func TestDrainSettlesOnShutdown(t *testing.T) {
+	repository := &fakeRepository{batches: [][]model.OutboxEvent{{{ID: 1}}}}
+	relay := newTestRelay(repository)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	events, _ := repository.ClaimOutboxEvents(ctx, 2, relay.lease())
+	cancel()
+
+	published, err := relay.publishAll(ctx, events)
+	if err == nil || len(published) != 0 {
+		t.Fatalf("published %v with error %v after cancel", published, err)
+	}
+	if err = relay.settle(ctx, events, published); err != nil {
+		t.Fatalf("settle after cancel: %v", err)
+	}
+	if !reflect.DeepEqual(repository.released, []int64{1}) {
+		t.Errorf("released %v, want [1]", repository.released)
+	}
+}
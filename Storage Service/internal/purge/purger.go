@@ -0,0 +1,66 @@
+package purge
+
+import (
+	"StorageService/internal/config"
+	"context"
+	"go.uber.org/zap"
+	"time"
+)
+
+type Repository interface {
+	PurgeDeleted(ctx context.Context, olderThan time.Duration) (stores, versions int64, err error)
+}
+
+// Purger removes deleted stores and versions for good once they are older than the retention period
+type Purger struct {
+	repository Repository
+	cfg        *config.PurgeConfig
+	logger     *zap.Logger
+}
+
+func NewPurger(repository Repository, cfg *config.PurgeConfig, logger *zap.Logger) *Purger {
+	return &Purger{
+		repository: repository,
+		cfg:        cfg,
+		logger:     logger.With(zap.String("place", "Purger")),
+	}
+}
+
+// Run purges every interval until ctx is cancelled. It returns at once if the retention is zero
+func (p *Purger) Run(ctx context.Context) {
+	if p.cfg.Retention <= 0 || p.cfg.Interval <= 0 {
+		p.logger.Info("Purging deleted stores is turned off")
+		return
+	}
+
+	ticker := time.NewTicker(p.cfg.Interval)
+	defer ticker.Stop()
+
+	for {
+		p.Purge(ctx)
+
+		select {
+		case <-ctx.Done():
+			p.logger.Info("Purger stopped")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Purge removes stores and versions deleted more than the retention period ago
+func (p *Purger) Purge(ctx context.Context) {
+	stores, versions, err := p.repository.PurgeDeleted(ctx, p.cfg.Retention)
+	if err != nil {
+		if ctx.Err() == nil {
+			p.logger.With(zap.Error(err)).Error("Failed to purge deleted stores")
+		}
+		return
+	}
+
+	if stores > 0 || versions > 0 {
+		p.logger.Info("Purged deleted stores",
+			zap.Int64("stores", stores),
+			zap.Int64("versions", versions))
+	}
+}
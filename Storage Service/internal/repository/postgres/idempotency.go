@@ -0,0 +1,53 @@
+package postgres
+
+import (
+	"StorageService/internal/model"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"github.com/jmoiron/sqlx"
+)
+
+// findIdempotentResult loads the outcome saved for the key into result.
+// It returns false when the key is seen for the first time
+func findIdempotentResult(ctx context.Context, tx *sqlx.Tx, login, action string, request model.IdempotentRequest, result interface{}) (bool, error) {
+	var saved model.IdempotencyKey
+	err := tx.GetContext(ctx, &saved, `
+        SELECT login, idempotency_key, action, request_hash, response
+        FROM idempotency_keys
+        WHERE login = $1 AND idempotency_key = $2
+    `, login, request.Key)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	if saved.Action != action || saved.RequestHash != request.RequestHash {
+		return false, model.ErrIdempotencyKeyConflict
+	}
+
+	return true, json.Unmarshal(saved.Response, result)
+}
+
+// saveIdempotentResult records the outcome in the transaction that produced it
+func saveIdempotentResult(ctx context.Context, tx *sqlx.Tx, login, action string, request model.IdempotentRequest, result interface{}) error {
+	response, err := json.Marshal(result)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.NamedExecContext(ctx, `
+        INSERT INTO idempotency_keys (login, idempotency_key, action, request_hash, response)
+        VALUES (:login, :idempotency_key, :action, :request_hash, :response)
+    `, model.IdempotencyKey{
+		Login:       login,
+		Key:         request.Key,
+		Action:      action,
+		RequestHash: request.RequestHash,
+		Response:    response,
+	})
+
+	return err
+}
@@ -0,0 +1,126 @@
+package postgres
+
+import (
+	"StorageService/internal/model"
+	"context"
+	"encoding/json"
+	"github.com/jmoiron/sqlx"
+	"sort"
+	"time"
+)
+
+// addOutboxEvent saves the event in the transaction of the change it describes
+func addOutboxEvent(ctx context.Context, tx sqlx.ExecerContext, eventType, storeID string, payload interface{}) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, `
+        INSERT INTO outbox (store_id, event_type, payload)
+        VALUES ($1, $2, $3)
+    `, storeID, eventType, data)
+
+	return err
+}
+
+// outboxClaimLock serializes claims, so a claim sees the events claimed by the previous one
+const outboxClaimLock = 4_242_001
+
+// ClaimOutboxEvents claims up to limit unpublished events for lease in the order they were saved.
+// Events of a store with an earlier event claimed by another relay are left for later, so events
+// of a store never overtake each other. The claim is committed before the events are published,
+// no rows stay locked while the relay waits for the broker
+func (r *Repository) ClaimOutboxEvents(ctx context.Context, limit int, lease time.Duration) ([]model.OutboxEvent, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if _, err = tx.ExecContext(ctx, "SELECT pg_advisory_xact_lock($1)", outboxClaimLock); err != nil {
+		return nil, err
+	}
+
+	var events []model.OutboxEvent
+	err = tx.SelectContext(ctx, &events, `
+        UPDATE outbox SET claimed_until = now() + make_interval(secs => $2)
+        WHERE id IN (
+            SELECT o.id
+            FROM outbox o
+            WHERE o.published_at IS NULL
+              AND (o.claimed_until IS NULL OR o.claimed_until < now())
+              AND NOT EXISTS (
+                  SELECT 1
+                  FROM outbox e
+                  WHERE e.store_id = o.store_id
+                    AND e.id < o.id
+                    AND e.published_at IS NULL
+                    AND e.claimed_until >= now()
+              )
+            ORDER BY o.id
+            LIMIT $1
+        )
+        RETURNING id, store_id, event_type, payload, created_at
+    `, limit, lease.Seconds())
+	if err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	// RETURNING doesn't keep the order of the subquery
+	sort.Slice(events, func(i, j int) bool {
+		return events[i].ID < events[j].ID
+	})
+
+	return events, nil
+}
+
+// SettleOutboxEvents marks published events as sent and releases the claim on the released ones,
+// so the next claim takes them again
+func (r *Repository) SettleOutboxEvents(ctx context.Context, published, released []int64) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if len(published) > 0 {
+		if err = execIn(ctx, tx, "UPDATE outbox SET published_at = now(), claimed_until = NULL WHERE id IN (?)", published); err != nil {
+			return err
+		}
+	}
+	if len(released) > 0 {
+		if err = execIn(ctx, tx, "UPDATE outbox SET claimed_until = NULL WHERE id IN (?)", released); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// DeletePublishedOutboxEvents removes events published more than olderThan ago
+func (r *Repository) DeletePublishedOutboxEvents(ctx context.Context, olderThan time.Duration) (int64, error) {
+	result, err := r.db.ExecContext(ctx, `
+        DELETE FROM outbox
+        WHERE published_at < now() - make_interval(secs => $1)
+    `, olderThan.Seconds())
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
+
+func execIn(ctx context.Context, tx *sqlx.Tx, query string, ids []int64) error {
+	query, args, err := sqlx.In(query, ids)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, tx.Rebind(query), args...)
+	return err
+}
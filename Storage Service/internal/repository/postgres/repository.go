@@ -1,8 +1,10 @@
 package postgres
 
 import (
+	"Contract"
 	"StorageService/internal/config"
 	"StorageService/internal/model"
+	"StorageService/internal/requestid"
 	"context"
 	"database/sql"
 	"fmt"
@@ -10,6 +12,8 @@ import (
 	_ "github.com/lib/pq"
 	"go.uber.org/zap"
 	"strconv"
+	"strings"
+	"time"
 )
 
 func ConnectToPostgresDB(cfg *config.DB, logger *zap.Logger) (*sqlx.DB, error) {
@@ -38,120 +42,363 @@ func ConnectToPostgresDB(cfg *config.DB, logger *zap.Logger) (*sqlx.DB, error) {
 type Repository struct {
 	db        *sqlx.DB
 	txOptions *sql.TxOptions
+	logger    *zap.Logger
 }
 
-func NewPostgresRepository(db *sqlx.DB, txOpts *sql.TxOptions) *Repository {
+func NewPostgresRepository(db *sqlx.DB, txOpts *sql.TxOptions, logger *zap.Logger) *Repository {
 	repo := &Repository{
 		db:        db,
 		txOptions: txOpts,
+		logger:    logger,
 	}
 
 	return repo
 }
 
+// loggerFor returns the repository logger annotated with the request id and the method name
+func (r *Repository) loggerFor(ctx context.Context, place string) *zap.Logger {
+	return requestid.Logger(ctx, r.logger).With(zap.String("place", place))
+}
+
 func (r *Repository) Close() error {
 	return r.db.Close()
 }
 
-func (r *Repository) CreateStore(store model.Store) error {
-	tx, err := r.db.Beginx()
+// CreateStore inserts the store with its first version. A request with a key already used
+// by the creator returns the store created the first time instead of inserting a new one
+func (r *Repository) CreateStore(ctx context.Context, store model.Store, idempotent *model.IdempotentRequest) (*model.Store, error) {
+	logger := r.loggerFor(ctx, "CreateStore")
+
+	tx, err := r.db.BeginTxx(ctx, nil)
 	if err != nil {
-		return err
+		return nil, err
 	}
+	defer func() { _ = tx.Rollback() }()
 
-	_, err = tx.Exec("SET TRANSACTION ISOLATION LEVEL SERIALIZABLE")
+	_, err = tx.ExecContext(ctx, "SET TRANSACTION ISOLATION LEVEL SERIALIZABLE")
 	if err != nil {
-		tx.Rollback()
-		return err
+		return nil, err
 	}
 
-	defer func() {
+	if idempotent != nil {
+		var saved model.Store
+		found, err := findIdempotentResult(ctx, tx, store.CreatorLogin, contract.ActionCreateStore, *idempotent, &saved)
 		if err != nil {
-			tx.Rollback()
-		} else {
-			tx.Commit()
+			return nil, err
+		}
+		if found {
+			logger.Info("Idempotency key seen before, returning saved store", zap.Int("storeId", saved.StoreID))
+			return &saved, nil
 		}
-	}()
+	}
 
 	storeQuery := `
-        INSERT INTO stores (name, address, creator_login, owner_name, opening_time, closing_time, created_at)
-        VALUES (:name, :address, :creator_login, :owner_name, :opening_time, :closing_time, :created_at)
+        INSERT INTO stores (name, address, city, street, house, postal_code, unit, creator_login, owner_name,
+                            opening_time, closing_time, created_at, last_version_number)
+        VALUES (:name, :address, :city, :street, :house, :postal_code, :unit, :creator_login, :owner_name,
+                :opening_time, :closing_time, :created_at, 1)
         RETURNING store_id
     `
 
 	var storeID int
 	namedQuery, args, err := sqlx.Named(storeQuery, store)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	err = tx.QueryRowx(tx.Rebind(namedQuery), args...).Scan(&storeID)
+	err = tx.QueryRowxContext(ctx, tx.Rebind(namedQuery), args...).Scan(&storeID)
 	if err != nil {
-		return err
+		return nil, err
 	}
+	store.StoreID = storeID
+	store.LatestVersionNumber = 1
+	store.Revision = 1
 	storeIdStr := strconv.Itoa(storeID)
 
 	version := model.StoreVersion{
 		StoreID:       storeIdStr,
 		VersionNumber: 1,
 		CreatorLogin:  store.CreatorLogin,
+		Name:          store.Name,
+		Address:       store.Address,
 		OwnerName:     store.OwnerName,
 		OpeningTime:   store.OpeningTime,
 		ClosingTime:   store.ClosingTime,
 		CreatedAt:     store.CreatedAt,
 		IsLast:        true,
+
+		StructuredAddress: store.StructuredAddress,
 	}
 	versionQuery := `
-        INSERT INTO store_versions (store_id, version_number, creator_login, owner_name,
-                                    opening_time, closing_time, created_at, is_last)
-        VALUES ( :store_id, :version_number, :creator_login, :owner_name,
-                :opening_time, :closing_time, :created_at, :is_last)
+        INSERT INTO store_versions (store_id, version_number, creator_login, name, address, city, street, house,
+                                    postal_code, unit, owner_name, opening_time, closing_time, created_at, is_last)
+        VALUES ( :store_id, :version_number, :creator_login, :name, :address, :city, :street, :house,
+                :postal_code, :unit, :owner_name, :opening_time, :closing_time, :created_at, :is_last)
     `
-	_, err = tx.NamedExec(versionQuery, version)
+	_, err = tx.NamedExecContext(ctx, versionQuery, version)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	return nil
+	err = addOutboxEvent(ctx, tx, model.EventStoreCreated, storeIdStr, store)
+	if err != nil {
+		return nil, err
+	}
+
+	if idempotent != nil {
+		err = saveIdempotentResult(ctx, tx, store.CreatorLogin, contract.ActionCreateStore, *idempotent, store)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		logger.Error("Failed to commit store insert", zap.Error(err))
+		return nil, err
+	}
+
+	logger.Info("Store inserted", zap.Int("storeId", storeID))
+
+	return &store, nil
 }
 
-func (r *Repository) CreateStoreVersion(storeVersion model.StoreVersion) error {
-	tx, err := r.db.Beginx()
+// CreateStoreVersion inserts the version as the last one of the store. A request with a key
+// already used by the creator returns the version created the first time. A set precondition fails with
+// model.ErrVersionMismatch when the store is missing or deleted, or its revision is not one of the expected ones
+func (r *Repository) CreateStoreVersion(ctx context.Context, storeVersion model.StoreVersion, idempotent *model.IdempotentRequest, precondition model.StorePrecondition) (*model.StoreVersion, error) {
+	logger := r.loggerFor(ctx, "CreateStoreVersion")
+
+	tx, err := r.db.BeginTxx(ctx, nil)
 	if err != nil {
-		return err
+		return nil, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	_, err = tx.ExecContext(ctx, "SET TRANSACTION ISOLATION LEVEL SERIALIZABLE")
+	if err != nil {
+		return nil, err
+	}
+
+	if idempotent != nil {
+		var saved model.StoreVersion
+		found, err := findIdempotentResult(ctx, tx, storeVersion.CreatorLogin, contract.ActionCreateStoreVersion, *idempotent, &saved)
+		if err != nil {
+			return nil, err
+		}
+		if found {
+			logger.Info("Idempotency key seen before, returning saved store version", zap.Int("versionId", saved.VersionID))
+			return &saved, nil
+		}
+	}
+
+	if precondition.IsSet() {
+		var revision int64
+		err = tx.QueryRowContext(ctx, "SELECT revision FROM stores WHERE store_id = $1 AND deleted_at IS NULL", storeVersion.StoreID).Scan(&revision)
+		if err != nil && err != sql.ErrNoRows {
+			return nil, err
+		}
+		// a missing or deleted store has revision 0, which matches no ETag
+		if revision == 0 || len(precondition.Revisions) > 0 && !containsRevision(precondition.Revisions, revision) {
+			logger.Info("Store has changed",
+				zap.Int64s("expectedRevisions", precondition.Revisions),
+				zap.Int64("revision", revision))
+			return nil, model.ErrVersionMismatch
+		}
+	}
+
+	err = insertLastVersion(ctx, tx, &storeVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	err = addOutboxEvent(ctx, tx, model.EventStoreVersionCreated, storeVersion.StoreID, storeVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	if idempotent != nil {
+		err = saveIdempotentResult(ctx, tx, storeVersion.CreatorLogin, contract.ActionCreateStoreVersion, *idempotent, storeVersion)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, err
+	}
+
+	logger.Info("Store version inserted",
+		zap.String("storeId", storeVersion.StoreID),
+		zap.Int("versionNumber", storeVersion.VersionNumber))
+
+	return &storeVersion, nil
+}
+
+// RestoreStoreVersion inserts a new last version of the store copying the owner and hours
+// of the restored version. The store, creator and creation time are taken from storeVersion.
+// Returns sql.ErrNoRows if the store has no such version
+func (r *Repository) RestoreStoreVersion(ctx context.Context, storeVersion model.StoreVersion, restoredVersionId string) (*model.StoreVersion, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	_, err = tx.ExecContext(ctx, "SET TRANSACTION ISOLATION LEVEL SERIALIZABLE")
+	if err != nil {
+		return nil, err
+	}
+
+	var restored model.StoreVersion
+	err = tx.GetContext(ctx, &restored, `
+        SELECT version_id, store_id, version_number, creator_login, name, address, owner_name, opening_time, closing_time,
+               created_at, is_last, city, street, house, postal_code, unit, restored_from_version_id
+        FROM store_versions
+        WHERE version_id = $1 AND store_id = $2 AND deleted_at IS NULL
+    `, restoredVersionId, storeVersion.StoreID)
+	if err != nil {
+		return nil, err
+	}
+
+	storeVersion.Name = restored.Name
+	storeVersion.Address = restored.Address
+	storeVersion.StructuredAddress = restored.StructuredAddress
+	storeVersion.OwnerName = restored.OwnerName
+	storeVersion.OpeningTime = restored.OpeningTime
+	storeVersion.ClosingTime = restored.ClosingTime
+	storeVersion.RestoredFromVersionID = &restored.VersionID
+
+	err = insertLastVersion(ctx, tx, &storeVersion)
+	if err != nil {
+		return nil, err
 	}
 
-	_, err = tx.Exec("SET TRANSACTION ISOLATION LEVEL SERIALIZABLE")
+	err = addOutboxEvent(ctx, tx, model.EventStoreVersionRestored, storeVersion.StoreID, storeVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, err
+	}
+
+	r.loggerFor(ctx, "RestoreStoreVersion").Info("Store version restored",
+		zap.String("storeId", storeVersion.StoreID),
+		zap.Int("restoredVersionId", restored.VersionID),
+		zap.Int("versionNumber", storeVersion.VersionNumber))
+
+	return &storeVersion, nil
+}
+
+// insertLastVersion inserts the version with the next version number and makes it the last one of the store.
+// Empty fields are carried over from the previous last version
+func insertLastVersion(ctx context.Context, tx *sqlx.Tx, storeVersion *model.StoreVersion) error {
+	// the counter on the store is never decremented, so numbers of deleted and purged versions are not reused.
+	// Updating it also locks the store row, versions of the same store are inserted one at a time
+	err := tx.QueryRowContext(ctx, `
+        UPDATE stores
+        SET last_version_number = last_version_number + 1, revision = revision + 1
+        WHERE store_id = $1
+        RETURNING last_version_number, revision
+    `, storeVersion.StoreID).Scan(&storeVersion.VersionNumber, &storeVersion.StoreRevision)
 	if err != nil {
-		tx.Rollback()
 		return err
 	}
 
-	var previousVersion model.StoreVersion
-	err = tx.Get(&previousVersion, "SELECT * FROM store_versions WHERE store_id = $1 AND is_last = true", storeVersion.StoreID)
+	var previous model.StoreVersion
+	err = tx.GetContext(ctx, &previous, `
+        SELECT name, address, city, street, house, postal_code, unit, owner_name, opening_time, closing_time
+        FROM store_versions
+        WHERE store_id = $1 AND is_last = true
+    `, storeVersion.StoreID)
 	if err != nil && err != sql.ErrNoRows {
-		tx.Rollback()
 		return err
 	}
+	carryOver(storeVersion, &previous)
 
-	if previousVersion.StoreID != "" {
-		previousVersion.IsLast = false
-		_, err = tx.Exec("UPDATE store_versions SET is_last = false WHERE store_id = $1 AND is_last = true", storeVersion.StoreID)
-		if err != nil {
-			tx.Rollback()
-			return err
+	_, err = tx.ExecContext(ctx, "UPDATE store_versions SET is_last = false WHERE store_id = $1 AND is_last = true", storeVersion.StoreID)
+	if err != nil {
+		return err
+	}
+
+	storeVersion.IsLast = true
+
+	return tx.QueryRowContext(ctx, `INSERT INTO store_versions (store_id, version_number, creator_login, name, address,
+                            city, street, house, postal_code, unit, owner_name, opening_time, closing_time, created_at, is_last,
+                            restored_from_version_id)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16)
+		RETURNING version_id`,
+		storeVersion.StoreID, storeVersion.VersionNumber, storeVersion.CreatorLogin, storeVersion.Name, storeVersion.Address,
+		storeVersion.City, storeVersion.Street, storeVersion.House, storeVersion.PostalCode, storeVersion.Unit,
+		storeVersion.OwnerName, storeVersion.OpeningTime, storeVersion.ClosingTime, storeVersion.CreatedAt, storeVersion.IsLast,
+		storeVersion.RestoredFromVersionID).Scan(&storeVersion.VersionID)
+}
+
+// carryOver fills the empty fields of the version with the values of the previous one
+func carryOver(storeVersion, previous *model.StoreVersion) {
+	fields := []struct {
+		value    *string
+		previous string
+	}{
+		{&storeVersion.Name, previous.Name},
+		{&storeVersion.Address, previous.Address},
+		{&storeVersion.OwnerName, previous.OwnerName},
+		{&storeVersion.OpeningTime, previous.OpeningTime},
+		{&storeVersion.ClosingTime, previous.ClosingTime},
+	}
+
+	// the parts go with the address string
+	if storeVersion.Address == "" {
+		storeVersion.StructuredAddress = previous.StructuredAddress
+	}
+
+	for _, field := range fields {
+		if *field.value == "" {
+			*field.value = field.previous
 		}
 	}
+}
 
-	storeVersion.VersionNumber = previousVersion.VersionNumber + 1
+// DeleteStore marks the store and its versions as deleted by login. The rows stay until they are purged.
+// Returns sql.ErrNoRows if the store is already deleted
+func (r *Repository) DeleteStore(ctx context.Context, storeId, login string) error {
+	tx, err := r.db.BeginTxx(ctx, r.txOptions)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
 
-	_, err = tx.Exec(`INSERT INTO store_versions (store_id, version_number, creator_login,
-                            owner_name, opening_time, closing_time, created_at, is_last)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`,
-		storeVersion.StoreID, storeVersion.VersionNumber, storeVersion.CreatorLogin, storeVersion.OwnerName,
-		storeVersion.OpeningTime, storeVersion.ClosingTime, storeVersion.CreatedAt, storeVersion.IsLast)
+	// now() is the same within the transaction, UndeleteStore uses it to find versions deleted with the store
+	query := `
+        UPDATE stores
+        SET deleted_at = now(), deleted_by = $2, revision = revision + 1
+        WHERE store_id = $1 AND deleted_at IS NULL
+    `
+	result, err := tx.ExecContext(ctx, query, storeId, login)
+	if err == nil {
+		err = requireAffected(result)
+	}
+	if err != nil {
+		return err
+	}
+
+	query = `
+        UPDATE store_versions
+        SET deleted_at = now(), deleted_by = $2
+        WHERE store_id = $1 AND deleted_at IS NULL
+    `
+	_, err = tx.ExecContext(ctx, query, storeId, login)
+	if err != nil {
+		return err
+	}
 
+	err = addOutboxEvent(ctx, tx, model.EventStoreDeleted, storeId, map[string]string{
+		"storeId":   storeId,
+		"deletedBy": login,
+	})
 	if err != nil {
-		tx.Rollback()
 		return err
 	}
 
@@ -160,73 +407,230 @@ func (r *Repository) CreateStoreVersion(storeVersion model.StoreVersion) error {
 		return err
 	}
 
+	r.loggerFor(ctx, "DeleteStore").Info("Store deleted", zap.String("storeId", storeId))
+
 	return nil
 }
 
-func (r *Repository) DeleteStore(storeId string) error {
-	tx, err := r.db.BeginTx(context.Background(), r.txOptions)
+// DeleteStoreVersion marks the version as deleted by login and makes the newest remaining version the last one.
+// Returns model.ErrOnlyVersion for the only remaining version and sql.ErrNoRows if it is already deleted
+func (r *Repository) DeleteStoreVersion(ctx context.Context, versionId, login string) error {
+	tx, err := r.db.BeginTxx(ctx, r.txOptions)
 	if err != nil {
 		return err
 	}
+	defer func() { _ = tx.Rollback() }()
 
-	err = r.DeleteStoreVersions(storeId)
+	var storeId string
+	var id int
+	err = tx.QueryRowContext(ctx, "SELECT store_id, version_id FROM store_versions WHERE version_id = $1 AND deleted_at IS NULL",
+		versionId).Scan(&storeId, &id)
 	if err != nil {
-		_ = tx.Rollback()
 		return err
 	}
 
+	// locking the remaining versions keeps concurrent deletes from removing all of them
+	var remaining []int
+	err = tx.SelectContext(ctx, &remaining, `
+        SELECT version_id
+        FROM store_versions
+        WHERE store_id = $1 AND deleted_at IS NULL
+        FOR UPDATE
+    `, storeId)
+	if err != nil {
+		return err
+	}
+
+	if !containsVersion(remaining, id) {
+		return sql.ErrNoRows
+	}
+	if len(remaining) == 1 {
+		return model.ErrOnlyVersion
+	}
+
 	query := `
-        DELETE FROM stores
-        WHERE store_id = $1
+        UPDATE store_versions
+        SET deleted_at = now(), deleted_by = $2, is_last = false
+        WHERE version_id = $1
     `
-	_, err = tx.Exec(query, storeId)
+	_, err = tx.ExecContext(ctx, query, id, login)
+	if err == nil {
+		err = refreshLastVersion(ctx, tx, storeId)
+	}
+	if err == nil {
+		err = bumpRevision(ctx, tx, storeId)
+	}
+	if err != nil {
+		return err
+	}
+
+	err = addOutboxEvent(ctx, tx, model.EventStoreVersionDeleted, storeId, map[string]string{
+		"storeId":   storeId,
+		"versionId": versionId,
+		"deletedBy": login,
+	})
 	if err != nil {
-		_ = tx.Rollback()
 		return err
 	}
 
 	err = tx.Commit()
 	if err != nil {
-		_ = tx.Rollback()
 		return err
 	}
 
+	r.loggerFor(ctx, "DeleteStoreVersion").Info("Store version deleted", zap.String("versionId", versionId))
+
 	return nil
 }
 
-func (r *Repository) DeleteStoreVersion(versionId string) error {
-	tx, err := r.db.BeginTx(context.Background(), r.txOptions)
+// UndeleteStore brings back the store together with the versions deleted with it.
+// Versions deleted on their own before stay deleted. Returns sql.ErrNoRows if the store isn't deleted
+func (r *Repository) UndeleteStore(ctx context.Context, storeId string) error {
+	tx, err := r.db.BeginTxx(ctx, r.txOptions)
 	if err != nil {
 		return err
 	}
+	defer func() { _ = tx.Rollback() }()
 
 	query := `
-        DELETE FROM store_versions
-        WHERE version_id = $1
+        UPDATE store_versions AS v
+        SET deleted_at = NULL, deleted_by = NULL
+        FROM stores AS s
+        WHERE s.store_id = $1 AND v.store_id = s.store_id AND v.deleted_at = s.deleted_at
+    `
+	_, err = tx.ExecContext(ctx, query, storeId)
+	if err != nil {
+		return err
+	}
+
+	query = `
+        UPDATE stores
+        SET deleted_at = NULL, deleted_by = NULL, revision = revision + 1
+        WHERE store_id = $1 AND deleted_at IS NOT NULL
     `
-	_, err = tx.Exec(query, versionId)
+	result, err := tx.ExecContext(ctx, query, storeId)
+	if err == nil {
+		err = requireAffected(result)
+	}
+	if err == nil {
+		err = refreshLastVersion(ctx, tx, storeId)
+	}
+	if err != nil {
+		return err
+	}
+
+	err = addOutboxEvent(ctx, tx, model.EventStoreUndeleted, storeId, map[string]string{"storeId": storeId})
 	if err != nil {
-		_ = tx.Rollback()
 		return err
 	}
 
 	err = tx.Commit()
 	if err != nil {
-		_ = tx.Rollback()
 		return err
 	}
 
+	r.loggerFor(ctx, "UndeleteStore").Info("Store undeleted", zap.String("storeId", storeId))
+
 	return nil
 }
 
-func (r *Repository) GetStoreByID(storeId string) (*model.Store, error) {
+// UndeleteStoreVersion brings back the deleted version. Returns sql.ErrNoRows if it isn't deleted
+func (r *Repository) UndeleteStoreVersion(ctx context.Context, versionId string) error {
+	tx, err := r.db.BeginTxx(ctx, r.txOptions)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
 	query := `
-        SELECT store_id, name, address, creator_login, owner_name, opening_time, closing_time, created_at
+        UPDATE store_versions
+        SET deleted_at = NULL, deleted_by = NULL
+        WHERE version_id = $1 AND deleted_at IS NOT NULL
+        RETURNING store_id
+    `
+	var storeId string
+	err = tx.QueryRowContext(ctx, query, versionId).Scan(&storeId)
+	if err == nil {
+		err = refreshLastVersion(ctx, tx, storeId)
+	}
+	if err == nil {
+		err = bumpRevision(ctx, tx, storeId)
+	}
+	if err != nil {
+		return err
+	}
+
+	err = addOutboxEvent(ctx, tx, model.EventStoreVersionUndeleted, storeId, map[string]string{
+		"storeId":   storeId,
+		"versionId": versionId,
+	})
+	if err != nil {
+		return err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return err
+	}
+
+	r.loggerFor(ctx, "UndeleteStoreVersion").Info("Store version undeleted", zap.String("versionId", versionId))
+
+	return nil
+}
+
+// PurgeDeleted removes stores and versions deleted more than olderThan ago for good
+func (r *Repository) PurgeDeleted(ctx context.Context, olderThan time.Duration) (stores, versions int64, err error) {
+	tx, err := r.db.BeginTxx(ctx, r.txOptions)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	// versions go first, they reference the stores
+	query := `
+        DELETE FROM store_versions
+        WHERE deleted_at < now() - make_interval(secs => $1)
+           OR store_id IN (SELECT store_id FROM stores WHERE deleted_at < now() - make_interval(secs => $1))
+    `
+	result, err := tx.ExecContext(ctx, query, olderThan.Seconds())
+	if err == nil {
+		versions, err = result.RowsAffected()
+	}
+	if err != nil {
+		return 0, 0, err
+	}
+
+	query = `
+        DELETE FROM stores
+        WHERE deleted_at < now() - make_interval(secs => $1)
+    `
+	result, err = tx.ExecContext(ctx, query, olderThan.Seconds())
+	if err == nil {
+		stores, err = result.RowsAffected()
+	}
+	if err != nil {
+		return 0, 0, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return 0, 0, err
+	}
+
+	return stores, versions, nil
+}
+
+// GetDeletedStoreByID returns the store only if it is deleted
+func (r *Repository) GetDeletedStoreByID(ctx context.Context, storeId string) (*model.Store, error) {
+	r.loggerFor(ctx, "GetDeletedStoreByID").Debug("Running query", zap.String("storeId", storeId))
+
+	query := `
+        SELECT store_id, name, address, creator_login, owner_name, opening_time, closing_time, created_at,
+               city, street, house, postal_code, unit
         FROM stores
-        WHERE store_id = $1
+        WHERE store_id = $1 AND deleted_at IS NOT NULL
     `
 	store := &model.Store{}
-	err := r.db.Get(store, query, storeId)
+	err := r.db.GetContext(ctx, store, query, storeId)
 	if err != nil {
 		return nil, err
 	}
@@ -234,15 +638,216 @@ func (r *Repository) GetStoreByID(storeId string) (*model.Store, error) {
 	return store, nil
 }
 
-func (r *Repository) GetStoreVersionHistory(storeId string) ([]*model.StoreVersion, error) {
+// GetDeletedStoreVersionForStore returns the version of the store only if it is deleted
+func (r *Repository) GetDeletedStoreVersionForStore(ctx context.Context, storeId, versionId string) (*model.StoreVersion, error) {
+	r.loggerFor(ctx, "GetDeletedStoreVersionForStore").Debug("Running query", zap.String("versionId", versionId))
+
 	query := `
-        SELECT version_id, store_id, version_number, creator_login, owner_name, opening_time, closing_time, created_at, is_last
+        SELECT version_id, store_id, version_number, creator_login, name, address, owner_name, opening_time, closing_time, created_at, is_last,
+               city, street, house, postal_code, unit, restored_from_version_id
         FROM store_versions
-        WHERE store_id = $1
-        ORDER BY created_at DESC
+        WHERE version_id = $1 AND store_id = $2 AND deleted_at IS NOT NULL
+    `
+	storeVersion := &model.StoreVersion{}
+	err := r.db.GetContext(ctx, storeVersion, query, versionId, storeId)
+	if err != nil {
+		return nil, err
+	}
+
+	return storeVersion, nil
+}
+
+// GetStoreByID returns the store with the name, address, owner and hours of its last version
+func (r *Repository) GetStoreByID(ctx context.Context, storeId string) (*model.Store, error) {
+	r.loggerFor(ctx, "GetStoreByID").Debug("Running query", zap.String("storeId", storeId))
+
+	query := `
+        SELECT s.store_id, COALESCE(v.name, s.name) AS name, COALESCE(v.address, s.address) AS address, s.creator_login,
+               COALESCE(v.owner_name, s.owner_name) AS owner_name, COALESCE(v.opening_time, s.opening_time) AS opening_time,
+               COALESCE(v.closing_time, s.closing_time) AS closing_time, s.created_at,
+               COALESCE(v.city, s.city) AS city, COALESCE(v.street, s.street) AS street, COALESCE(v.house, s.house) AS house,
+               COALESCE(v.postal_code, s.postal_code) AS postal_code, COALESCE(v.unit, s.unit) AS unit,
+               COALESCE(v.version_number, 0) AS latest_version_number, s.revision
+        FROM stores AS s
+        LEFT JOIN store_versions AS v ON v.store_id = s.store_id AND v.is_last = true
+        WHERE s.store_id = $1 AND s.deleted_at IS NULL
+    `
+	store := &model.Store{}
+	err := r.db.GetContext(ctx, store, query, storeId)
+	if err != nil {
+		return nil, err
+	}
+
+	return store, nil
+}
+
+// storeSortColumns maps model.StoreSortName and model.StoreSortCreatedAt to the columns of the ListStores query
+var storeSortColumns = map[string]string{
+	model.StoreSortName:      "v.name",
+	model.StoreSortCreatedAt: "s.created_at",
+}
+
+// ListStores returns up to filter.Limit live stores with the name, address, owner and hours of their last version,
+// ordered by the sort column and then by id. Pages continue after filter.AfterValue and filter.AfterID
+func (r *Repository) ListStores(ctx context.Context, filter model.StoreFilter) ([]*model.Store, error) {
+	r.loggerFor(ctx, "ListStores").Debug("Running query", zap.Any("filter", filter))
+
+	sortColumn, ok := storeSortColumns[filter.SortBy]
+	if !ok {
+		return nil, fmt.Errorf("unknown sort column %q", filter.SortBy)
+	}
+
+	direction, comparison := "ASC", ">"
+	if filter.Descending {
+		direction, comparison = "DESC", "<"
+	}
+
+	conditions := []string{"s.deleted_at IS NULL"}
+	var args []interface{}
+	addCondition := func(condition string, arg interface{}) {
+		args = append(args, arg)
+		conditions = append(conditions, strings.ReplaceAll(condition, "?", "$"+strconv.Itoa(len(args))))
+	}
+
+	if filter.CreatorLogin != "" {
+		addCondition("s.creator_login = ?", filter.CreatorLogin)
+	}
+	if filter.NamePrefix != "" {
+		// matches store_versions_last_name_prefix_idx, ILIKE can't use a btree index
+		addCondition(`lower(v.name) LIKE lower(?) ESCAPE '\'`, likePrefix(filter.NamePrefix))
+	}
+	if filter.City != "" {
+		addCondition("lower(v.city) = lower(?)", strings.TrimSpace(filter.City))
+	}
+	if !filter.CreatedFrom.IsZero() {
+		addCondition("s.created_at >= ?", filter.CreatedFrom)
+	}
+	if !filter.CreatedTo.IsZero() {
+		addCondition("s.created_at <= ?", filter.CreatedTo)
+	}
+	if filter.AfterID != 0 {
+		args = append(args, filter.AfterValue, filter.AfterID)
+		conditions = append(conditions, fmt.Sprintf("(%s, s.store_id) %s ($%d, $%d)", sortColumn, comparison, len(args)-1, len(args)))
+	}
+
+	args = append(args, filter.Limit)
+
+	// every live store has a live last version, so the inner join doesn't drop stores
+	query := fmt.Sprintf(`
+        SELECT s.store_id, v.name, v.address, s.creator_login, v.owner_name, v.opening_time, v.closing_time, s.created_at,
+               v.city, v.street, v.house, v.postal_code, v.unit, v.version_number AS latest_version_number, s.revision
+        FROM stores AS s
+        JOIN store_versions AS v ON v.store_id = s.store_id AND v.is_last = true
+        WHERE %s
+        ORDER BY %s %s, s.store_id %s
+        LIMIT $%d
+    `, strings.Join(conditions, " AND "), sortColumn, direction, direction, len(args))
+
+	stores := []*model.Store{}
+	err := r.db.SelectContext(ctx, &stores, query, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	return stores, nil
+}
+
+// ListCities returns up to limit cities of live stores with the number of stores in each, going by the city
+// of their last version. Cities are grouped and ordered by their lowercased name, which
+// store_versions_last_city_idx is built on. Pages continue after afterKey, empty on the first page
+func (r *Repository) ListCities(ctx context.Context, limit int, afterKey string) ([]*model.City, error) {
+	r.loggerFor(ctx, "ListCities").Debug("Running query", zap.Int("limit", limit), zap.String("afterKey", afterKey))
+
+	query := `
+        SELECT min(v.city) AS name, lower(v.city) AS city_key, count(*) AS stores
+        FROM store_versions AS v
+        JOIN stores AS s ON s.store_id = v.store_id
+        WHERE v.is_last AND s.deleted_at IS NULL AND v.city <> '' AND lower(v.city) > $1
+        GROUP BY lower(v.city)
+        ORDER BY lower(v.city)
+        LIMIT $2
+    `
+	cities := []*model.City{}
+	err := r.db.SelectContext(ctx, &cities, query, afterKey, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	return cities, nil
+}
+
+// SearchStores returns up to limit live stores whose last version matches any word of the text in its name,
+// address or owner. Stores matching every word come first, then the best match. Stop words are dropped
+// by the store_search config, so they neither find nor rank stores. The search vector is a generated
+// column of store_versions, so every inserted version is indexed by itself
+func (r *Repository) SearchStores(ctx context.Context, text string, limit int) ([]*model.StoreSearchResult, error) {
+	r.loggerFor(ctx, "SearchStores").Debug("Running query", zap.String("text", text), zap.Int("limit", limit))
+
+	words := strings.Fields(text)
+	if len(words) == 0 {
+		return []*model.StoreSearchResult{}, nil
+	}
+
+	// plainto_tsquery requires every word, any_words finds stores matching only some of them too
+	query := `
+        SELECT s.store_id, v.name, v.address, s.creator_login, v.owner_name, v.opening_time, v.closing_time, s.created_at,
+               v.city, v.street, v.house, v.postal_code, v.unit, v.version_number AS latest_version_number, s.revision,
+               ts_rank(v.search_vector, q.any_words) AS rank,
+               ts_headline('store_search', v.name, q.any_words, 'HighlightAll=true') AS name_highlight,
+               ts_headline('store_search', v.address, q.any_words, 'HighlightAll=true') AS address_highlight,
+               ts_headline('store_search', v.owner_name, q.any_words, 'HighlightAll=true') AS owner_name_highlight
+        FROM (
+            SELECT plainto_tsquery('store_search', $1) AS all_words, ` + anyWordsQuery(len(words), 3) + ` AS any_words
+        ) AS q
+        CROSS JOIN stores AS s
+        JOIN store_versions AS v ON v.store_id = s.store_id AND v.is_last = true
+        WHERE s.deleted_at IS NULL AND v.search_vector @@ q.any_words
+        ORDER BY v.search_vector @@ q.all_words DESC, rank DESC, s.store_id
+        LIMIT $2
+    `
+	args := []interface{}{text, limit}
+	for _, word := range words {
+		args = append(args, word)
+	}
+
+	results := []*model.StoreSearchResult{}
+	err := r.db.SelectContext(ctx, &results, query, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
+
+// anyWordsQuery ORs the tsqueries of words parameters, numbered from first on. Every word is parsed
+// by plainto_tsquery on its own, so tsquery operators in the text are searched for as plain text
+func anyWordsQuery(words, first int) string {
+	queries := make([]string, 0, words)
+	for i := 0; i < words; i++ {
+		queries = append(queries, fmt.Sprintf("plainto_tsquery('store_search', $%d)", first+i))
+	}
+	return "(" + strings.Join(queries, " || ") + ")"
+}
+
+// likePrefix escapes the LIKE wildcards in prefix and matches everything starting with it
+func likePrefix(prefix string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(prefix) + "%"
+}
+
+// GetStoreVersionHistory returns the live versions of the store, newest first. Versions are ordered
+// by number, which grows with every version, so versions created within the same microsecond keep their order
+func (r *Repository) GetStoreVersionHistory(ctx context.Context, storeId string) ([]*model.StoreVersion, error) {
+	r.loggerFor(ctx, "GetStoreVersionHistory").Debug("Running query", zap.String("storeId", storeId))
+
+	query := `
+        SELECT version_id, store_id, version_number, creator_login, name, address, owner_name, opening_time, closing_time, created_at, is_last,
+               city, street, house, postal_code, unit, restored_from_version_id
+        FROM store_versions
+        WHERE store_id = $1 AND deleted_at IS NULL
+        ORDER BY version_number DESC
     `
 	storeVersions := []*model.StoreVersion{}
-	err := r.db.Select(&storeVersions, query, storeId)
+	err := r.db.SelectContext(ctx, &storeVersions, query, storeId)
 	if err != nil {
 		return nil, err
 	}
@@ -250,14 +855,89 @@ func (r *Repository) GetStoreVersionHistory(storeId string) ([]*model.StoreVersi
 	return storeVersions, nil
 }
 
-func (r *Repository) GetStoreVersionByID(versionId string) (*model.StoreVersion, error) {
+// GetStoreVersionAt returns the version of the store that was the last one at the given creation time.
+// Returns sql.ErrNoRows if the store had no versions yet
+func (r *Repository) GetStoreVersionAt(ctx context.Context, storeId string, at time.Time) (*model.StoreVersion, error) {
+	r.loggerFor(ctx, "GetStoreVersionAt").Debug("Running query", zap.String("storeId", storeId), zap.Time("at", at))
+
 	query := `
-        SELECT version_id, store_id, version_number, creator_login, owner_name, opening_time, closing_time, created_at, is_last
+        SELECT version_id, store_id, version_number, creator_login, name, address, owner_name, opening_time, closing_time, created_at, is_last,
+               city, street, house, postal_code, unit, restored_from_version_id
         FROM store_versions
-        WHERE version_id = $1
+        WHERE store_id = $1 AND created_at <= $2 AND deleted_at IS NULL
+        ORDER BY version_number DESC
+        LIMIT 1
+    `
+	storeVersion := &model.StoreVersion{}
+	err := r.db.GetContext(ctx, storeVersion, query, storeId, at)
+	if err != nil {
+		return nil, err
+	}
+
+	return storeVersion, nil
+}
+
+// GetStoreVersionHistoryPage returns up to filter.Limit live versions of the store ordered by number.
+// Pages continue after filter.AfterNumber
+func (r *Repository) GetStoreVersionHistoryPage(ctx context.Context, storeId string, filter model.StoreVersionFilter) ([]*model.StoreVersion, error) {
+	r.loggerFor(ctx, "GetStoreVersionHistoryPage").Debug("Running query", zap.String("storeId", storeId), zap.Any("filter", filter))
+
+	direction, comparison := "ASC", ">"
+	if filter.Descending {
+		direction, comparison = "DESC", "<"
+	}
+
+	conditions := []string{"store_id = $1", "deleted_at IS NULL"}
+	args := []interface{}{storeId}
+	addCondition := func(condition string, arg interface{}) {
+		args = append(args, arg)
+		conditions = append(conditions, strings.ReplaceAll(condition, "?", "$"+strconv.Itoa(len(args))))
+	}
+
+	if filter.CreatorLogin != "" {
+		addCondition("creator_login = ?", filter.CreatorLogin)
+	}
+	if !filter.CreatedFrom.IsZero() {
+		addCondition("created_at >= ?", filter.CreatedFrom)
+	}
+	if !filter.CreatedTo.IsZero() {
+		addCondition("created_at <= ?", filter.CreatedTo)
+	}
+	if filter.AfterNumber != 0 {
+		addCondition("version_number "+comparison+" ?", filter.AfterNumber)
+	}
+
+	args = append(args, filter.Limit)
+
+	query := fmt.Sprintf(`
+        SELECT version_id, store_id, version_number, creator_login, name, address, owner_name, opening_time, closing_time, created_at, is_last,
+               city, street, house, postal_code, unit, restored_from_version_id
+        FROM store_versions
+        WHERE %s
+        ORDER BY version_number %s
+        LIMIT $%d
+    `, strings.Join(conditions, " AND "), direction, len(args))
+
+	storeVersions := []*model.StoreVersion{}
+	err := r.db.SelectContext(ctx, &storeVersions, query, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	return storeVersions, nil
+}
+
+func (r *Repository) GetStoreVersionByID(ctx context.Context, versionId string) (*model.StoreVersion, error) {
+	r.loggerFor(ctx, "GetStoreVersionByID").Debug("Running query", zap.String("versionId", versionId))
+
+	query := `
+        SELECT version_id, store_id, version_number, creator_login, name, address, owner_name, opening_time, closing_time, created_at, is_last,
+               city, street, house, postal_code, unit, restored_from_version_id
+        FROM store_versions
+        WHERE version_id = $1 AND deleted_at IS NULL
     `
 	storeVersion := &model.StoreVersion{}
-	err := r.db.Get(storeVersion, query, versionId)
+	err := r.db.GetContext(ctx, storeVersion, query, versionId)
 	if err != nil {
 		return nil, err
 	}
@@ -265,14 +945,17 @@ func (r *Repository) GetStoreVersionByID(versionId string) (*model.StoreVersion,
 	return storeVersion, nil
 }
 
-func (r *Repository) GetStoreVersionForStore(storeId, versionId string) (*model.StoreVersion, error) {
+func (r *Repository) GetStoreVersionForStore(ctx context.Context, storeId, versionId string) (*model.StoreVersion, error) {
+	r.loggerFor(ctx, "GetStoreVersionForStore").Debug("Running query", zap.String("versionId", versionId))
+
 	query := `
-        SELECT version_id, store_id, version_number, creator_login, owner_name, opening_time, closing_time, created_at, is_last
+        SELECT version_id, store_id, version_number, creator_login, name, address, owner_name, opening_time, closing_time, created_at, is_last,
+               city, street, house, postal_code, unit, restored_from_version_id
         FROM store_versions
-        WHERE version_id = $1 AND store_id = $2
+        WHERE version_id = $1 AND store_id = $2 AND deleted_at IS NULL
     `
 	storeVersion := &model.StoreVersion{}
-	err := r.db.Get(storeVersion, query, versionId, storeId)
+	err := r.db.GetContext(ctx, storeVersion, query, versionId, storeId)
 	if err != nil {
 		return nil, err
 	}
@@ -280,7 +963,9 @@ func (r *Repository) GetStoreVersionForStore(storeId, versionId string) (*model.
 	return storeVersion, nil
 }
 
-func (r *Repository) CheckStoreCreator(storeID, login string) error {
+func (r *Repository) CheckStoreCreator(ctx context.Context, storeID, login string) error {
+	r.loggerFor(ctx, "CheckStoreCreator").Debug("Running query", zap.String("storeId", storeID))
+
 	query := `
         SELECT 1
         FROM stores
@@ -288,7 +973,7 @@ func (r *Repository) CheckStoreCreator(storeID, login string) error {
         LIMIT 1
     `
 	var result int
-	err := r.db.QueryRow(query, storeID, login).Scan(&result)
+	err := r.db.QueryRowContext(ctx, query, storeID, login).Scan(&result)
 	if err != nil {
 		return err
 	}
@@ -296,28 +981,61 @@ func (r *Repository) CheckStoreCreator(storeID, login string) error {
 	return nil
 }
 
-func (r *Repository) DeleteStoreVersions(storeId string) error {
-
-	tx, err := r.db.BeginTx(context.Background(), r.txOptions)
+// refreshLastVersion makes the remaining version with the highest number the last one of the store.
+// The flag is cleared first, the unique index allows only one last version per store at any moment
+func refreshLastVersion(ctx context.Context, tx *sqlx.Tx, storeId string) error {
+	_, err := tx.ExecContext(ctx, "UPDATE store_versions SET is_last = false WHERE store_id = $1 AND is_last = true", storeId)
 	if err != nil {
 		return err
 	}
 
 	query := `
-        DELETE FROM store_versions
-        WHERE store_id = $1
+        UPDATE store_versions
+        SET is_last = true
+        WHERE version_id = (
+            SELECT version_id
+            FROM store_versions
+            WHERE store_id = $1 AND deleted_at IS NULL
+            ORDER BY version_number DESC
+            LIMIT 1
+        )
     `
-	_, err = tx.Exec(query, storeId)
-	if err != nil {
-		_ = tx.Rollback()
-		return err
+	_, err = tx.ExecContext(ctx, query, storeId)
+	return err
+}
+
+// bumpRevision marks a change of the store, so ETags issued before it no longer match
+func bumpRevision(ctx context.Context, tx *sqlx.Tx, storeId string) error {
+	_, err := tx.ExecContext(ctx, "UPDATE stores SET revision = revision + 1 WHERE store_id = $1", storeId)
+	return err
+}
+
+func containsRevision(revisions []int64, revision int64) bool {
+	for _, r := range revisions {
+		if r == revision {
+			return true
+		}
 	}
+	return false
+}
 
-	err = tx.Commit()
+func containsVersion(versionIds []int, versionId int) bool {
+	for _, id := range versionIds {
+		if id == versionId {
+			return true
+		}
+	}
+	return false
+}
+
+// requireAffected returns sql.ErrNoRows if the statement changed nothing
+func requireAffected(result sql.Result) error {
+	affected, err := result.RowsAffected()
 	if err != nil {
-		_ = tx.Rollback()
 		return err
 	}
-
+	if affected == 0 {
+		return sql.ErrNoRows
+	}
 	return nil
 }
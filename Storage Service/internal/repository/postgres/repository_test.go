@@ -0,0 +1,388 @@
+package postgres
+
+import (
+	"StorageService/internal/migration"
+	"StorageService/internal/model"
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/jmoiron/sqlx"
+	"go.uber.org/zap"
+	"os"
+	"reflect"
+	"strconv"
+	"testing"
+	"time"
+)
+
+// testDatabaseVariable holds the URL of a scratch Postgres database, the tests are skipped without it.
+// The database is migrated and its tables are emptied before every test
+const testDatabaseVariable = "STORAGE_TEST_DATABASE_URL"
+
+func newTestRepository(t *testing.T) *Repository {
+	url := os.Getenv(testDatabaseVariable)
+	if url == "" {
+		t.Skip(testDatabaseVariable + " is not set")
+	}
+
+	db, err := sqlx.Connect("postgres", url)
+	if err != nil {
+		t.Fatalf("connect: %v", err)
+	}
+	t.Cleanup(func() { _ = db.Close() })
+
+	if err = migration.NewMigration().Migrate(db); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	if _, err = db.Exec("TRUNCATE stores, store_versions, idempotency_keys, outbox RESTART IDENTITY"); err != nil {
+		t.Fatalf("truncate: %v", err)
+	}
+
+	return NewPostgresRepository(db, &sql.TxOptions{Isolation: sql.LevelSerializable}, zap.NewNop())
+}
+
+// createTestStore creates a store named name with its first version, created at the given time
+func createTestStore(t *testing.T, repo *Repository, name string, createdAt time.Time) *model.Store {
+	store, err := repo.CreateStore(context.Background(), model.Store{
+		Name:         name,
+		Address:      "Karaganda, Lenina, 143",
+		CreatorLogin: "user1",
+		OwnerName:    "Doe, John",
+		OpeningTime:  "08:00",
+		ClosingTime:  "20:00",
+		CreatedAt:    createdAt.UTC().Truncate(time.Microsecond),
+
+		StructuredAddress: model.StructuredAddress{City: "Karaganda", Street: "Lenina", House: "143"},
+	}, nil)
+	if err != nil {
+		t.Fatalf("create store %q: %v", name, err)
+	}
+	return store
+}
+
+// addTestVersion adds the version to the store, its empty fields are carried over
+func addTestVersion(t *testing.T, repo *Repository, store *model.Store, version model.StoreVersion) *model.StoreVersion {
+	version.StoreID = strconv.Itoa(store.StoreID)
+	if version.CreatorLogin == "" {
+		version.CreatorLogin = "user1"
+	}
+	if version.CreatedAt.IsZero() {
+		version.CreatedAt = time.Now().UTC().Truncate(time.Microsecond)
+	}
+
+	created, err := repo.CreateStoreVersion(context.Background(), version, nil, model.StorePrecondition{})
+	if err != nil {
+		t.Fatalf("add version to store %d: %v", store.StoreID, err)
+	}
+	return created
+}
+
+// lastVersionNumbers returns the numbers of the versions of the store marked as the last one
+func lastVersionNumbers(t *testing.T, repo *Repository, store *model.Store) []int {
+	var numbers []int
+	err := repo.db.Select(&numbers, "SELECT version_number FROM store_versions WHERE store_id = $1 AND is_last", strconv.Itoa(store.StoreID))
+	if err != nil {
+		t.Fatalf("read last versions: %v", err)
+	}
+	return numbers
+}
+
+func TestDeletesKeepOneLastVersion(t *testing.T) {
+	repo := newTestRepository(t)
+	ctx := context.Background()
+
+	store := createTestStore(t, repo, "Bakery", time.Now())
+	second := addTestVersion(t, repo, store, model.StoreVersion{OwnerName: "Doe, Jane"})
+	third := addTestVersion(t, repo, store, model.StoreVersion{OwnerName: "Roe, Richard"})
+
+	steps := []struct {
+		name string
+		run  func() error
+		last int
+	}{
+		{"delete the last version", func() error { return repo.DeleteStoreVersion(ctx, strconv.Itoa(third.VersionID), "user1") }, 2},
+		{"undelete it", func() error { return repo.UndeleteStoreVersion(ctx, strconv.Itoa(third.VersionID)) }, 3},
+		{"delete a middle version", func() error { return repo.DeleteStoreVersion(ctx, strconv.Itoa(second.VersionID), "user1") }, 3},
+		{"delete the store", func() error { return repo.DeleteStore(ctx, strconv.Itoa(store.StoreID), "user1") }, 3},
+		{"undelete the store", func() error { return repo.UndeleteStore(ctx, strconv.Itoa(store.StoreID)) }, 3},
+	}
+
+	for _, step := range steps {
+		if err := step.run(); err != nil {
+			t.Fatalf("%s: %v", step.name, err)
+		}
+		if numbers := lastVersionNumbers(t, repo, store); len(numbers) != 1 || numbers[0] != step.last {
+			t.Fatalf("%s: last versions %v, want [%d]", step.name, numbers, step.last)
+		}
+	}
+
+	latest, err := repo.GetStoreVersionAt(ctx, strconv.Itoa(store.StoreID), time.Now())
+	if err != nil {
+		t.Fatalf("get latest version: %v", err)
+	}
+	if latest.VersionNumber != 3 || latest.OwnerName != "Roe, Richard" {
+		t.Errorf("latest version is %d by %q, want version 3 by Roe, Richard", latest.VersionNumber, latest.OwnerName)
+	}
+
+	if err = repo.DeleteStoreVersion(ctx, strconv.Itoa(third.VersionID), "user1"); err != nil {
+		t.Fatalf("delete version 3 again: %v", err)
+	}
+	history, err := repo.GetStoreVersionHistory(ctx, strconv.Itoa(store.StoreID))
+	if err != nil || len(history) != 1 {
+		t.Fatalf("history %v with error %v, want the first version only", history, err)
+	}
+	err = repo.DeleteStoreVersion(ctx, strconv.Itoa(history[0].VersionID), "user1")
+	if !errors.Is(err, model.ErrOnlyVersion) {
+		t.Errorf("deleting the only version: got %v, want %v", err, model.ErrOnlyVersion)
+	}
+	if numbers := lastVersionNumbers(t, repo, store); len(numbers) != 1 || numbers[0] != 1 {
+		t.Errorf("last versions %v after deleting down to the first one, want [1]", numbers)
+	}
+}
+
+func TestNewVersionCarriesOverEmptyFields(t *testing.T) {
+	repo := newTestRepository(t)
+	ctx := context.Background()
+
+	store := createTestStore(t, repo, "Bakery", time.Now())
+	owner := addTestVersion(t, repo, store, model.StoreVersion{OwnerName: "Doe, Jane"})
+	renamed := addTestVersion(t, repo, store, model.StoreVersion{Name: "Corner Bakery", OpeningTime: "07:00"})
+
+	saved, err := repo.GetStoreVersionByID(ctx, strconv.Itoa(renamed.VersionID))
+	if err != nil {
+		t.Fatalf("get version: %v", err)
+	}
+
+	want := model.StoreVersion{
+		Name:        "Corner Bakery",
+		Address:     store.Address,
+		OwnerName:   owner.OwnerName,
+		OpeningTime: "07:00",
+		ClosingTime: store.ClosingTime,
+	}
+	got := model.StoreVersion{
+		Name:        saved.Name,
+		Address:     saved.Address,
+		OwnerName:   saved.OwnerName,
+		OpeningTime: saved.OpeningTime,
+		ClosingTime: saved.ClosingTime,
+	}
+	if got != want {
+		t.Errorf("version 3 has %+v, want %+v", got, want)
+	}
+
+	live, err := repo.GetStoreByID(ctx, strconv.Itoa(store.StoreID))
+	if err != nil {
+		t.Fatalf("get store: %v", err)
+	}
+	if live.Name != "Corner Bakery" || live.OwnerName != "Doe, Jane" || live.Address != store.Address {
+		t.Errorf("store reads %q by %q at %q, want the fields of version 3", live.Name, live.OwnerName, live.Address)
+	}
+}
+
+// listAll follows the pages of ListStores the way the service builds its cursors and returns the store names
+func listAll(t *testing.T, repo *Repository, filter model.StoreFilter) []string {
+	var names []string
+	for page := 0; ; page++ {
+		stores, err := repo.ListStores(context.Background(), filter)
+		if err != nil {
+			t.Fatalf("page %d: %v", page, err)
+		}
+		for _, store := range stores {
+			names = append(names, store.Name)
+		}
+		if len(stores) < filter.Limit {
+			return names
+		}
+
+		last := stores[len(stores)-1]
+		filter.AfterID = last.StoreID
+		filter.AfterValue = last.Name
+		if filter.SortBy == model.StoreSortCreatedAt {
+			filter.AfterValue = last.CreatedAt.Format(time.RFC3339Nano)
+		}
+	}
+}
+
+func TestListStoresPages(t *testing.T) {
+	repo := newTestRepository(t)
+
+	start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	for i, name := range []string{"Gamma", "Beta", "Alpha", "Beta", "Delta"} {
+		createTestStore(t, repo, name, start.Add(time.Duration(i)*time.Hour))
+	}
+
+	tests := []struct {
+		filter model.StoreFilter
+		want   []string
+	}{
+		{model.StoreFilter{SortBy: model.StoreSortName}, []string{"Alpha", "Beta", "Beta", "Delta", "Gamma"}},
+		{model.StoreFilter{SortBy: model.StoreSortName, Descending: true}, []string{"Gamma", "Delta", "Beta", "Beta", "Alpha"}},
+		{model.StoreFilter{SortBy: model.StoreSortCreatedAt}, []string{"Gamma", "Beta", "Alpha", "Beta", "Delta"}},
+		{model.StoreFilter{SortBy: model.StoreSortCreatedAt, Descending: true}, []string{"Delta", "Beta", "Alpha", "Beta", "Gamma"}},
+		{model.StoreFilter{SortBy: model.StoreSortName, CreatedFrom: start.Add(time.Hour), CreatedTo: start.Add(3 * time.Hour)},
+			[]string{"Alpha", "Beta", "Beta"}},
+	}
+
+	for _, tt := range tests {
+		// pages of two split the stores with the same name
+		tt.filter.Limit = 2
+		if got := listAll(t, repo, tt.filter); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%+v: got %v, want %v", tt.filter, got, tt.want)
+		}
+	}
+}
+
+func TestListStoresNamePrefixIsNotAPattern(t *testing.T) {
+	repo := newTestRepository(t)
+
+	for _, name := range []string{"50% Off", "500 Goods", "A_B Market", "AxB Market", `Back\Slash`, "Backyard"} {
+		createTestStore(t, repo, name, time.Now())
+	}
+
+	tests := []struct {
+		prefix string
+		want   []string
+	}{
+		{"50%", []string{"50% Off"}},
+		{"a_b", []string{"A_B Market"}},
+		{`back\`, []string{`Back\Slash`}},
+		{"BACK", []string{`Back\Slash`, "Backyard"}},
+		{"%", nil},
+	}
+
+	for _, tt := range tests {
+		got := listAll(t, repo, model.StoreFilter{NamePrefix: tt.prefix, SortBy: model.StoreSortName, Limit: 10})
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("prefix %q: got %v, want %v", tt.prefix, got, tt.want)
+		}
+	}
+}
+
+func TestSearchStoresRanking(t *testing.T) {
+	repo := newTestRepository(t)
+	ctx := context.Background()
+
+	stores := []model.Store{
+		{Name: "Flowers", Address: "Karaganda, Lenina, 143", OwnerName: "Doe, John"},
+		{Name: "Flowers", Address: "Astana, Abaya, 1", OwnerName: "Bakery, Bob"},
+		{Name: "Bakery", Address: "Astana, Abaya, 2", OwnerName: "Doe, John"},
+		{Name: "Bakery on Lenina", Address: "Astana, Abaya, 3", OwnerName: "Doe, John"},
+		{Name: "Pharmacy", Address: "Astana, Abaya, 4", OwnerName: "Doe, John"},
+	}
+	for _, store := range stores {
+		store.CreatorLogin = "user1"
+		store.OpeningTime, store.ClosingTime = "08:00", "20:00"
+		store.CreatedAt = time.Now().UTC().Truncate(time.Microsecond)
+		if _, err := repo.CreateStore(ctx, store, nil); err != nil {
+			t.Fatalf("create store %q: %v", store.Name, err)
+		}
+	}
+
+	tests := []struct {
+		text string
+		want []string
+	}{
+		// every word first, then a match in the name, the address and the owner
+		{"bakery on lenina", []string{"Bakery on Lenina", "Bakery", "Karaganda, Lenina, 143", "Bakery, Bob"}},
+		{"LENINA", []string{"Bakery on Lenina", "Karaganda, Lenina, 143"}},
+		{"bakery & !lenina", []string{"Bakery on Lenina", "Bakery", "Karaganda, Lenina, 143", "Bakery, Bob"}},
+		{"the on", nil},
+		{"bake", nil},
+	}
+
+	for _, tt := range tests {
+		results, err := repo.SearchStores(ctx, tt.text, 10)
+		if err != nil {
+			t.Fatalf("search %q: %v", tt.text, err)
+		}
+
+		// stores are told apart by the field that matched
+		var got []string
+		for _, result := range results {
+			switch {
+			case result.NameHighlight != result.Name:
+				got = append(got, result.Name)
+			case result.AddressHighlight != result.Address:
+				got = append(got, result.Address)
+			default:
+				got = append(got, result.OwnerName)
+			}
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("search %q: got %v, want %v", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestStoreVersionHistoryFilters(t *testing.T) {
+	repo := newTestRepository(t)
+	ctx := context.Background()
+
+	start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	store := createTestStore(t, repo, "Bakery", start)
+	storeID := strconv.Itoa(store.StoreID)
+	addTestVersion(t, repo, store, model.StoreVersion{OwnerName: "Doe, Jane", CreatorLogin: "user2", CreatedAt: start.Add(time.Hour)})
+	deleted := addTestVersion(t, repo, store, model.StoreVersion{OwnerName: "Roe, Richard", CreatedAt: start.Add(2 * time.Hour)})
+	addTestVersion(t, repo, store, model.StoreVersion{OwnerName: "Roe, Jane", CreatorLogin: "user2", CreatedAt: start.Add(3 * time.Hour)})
+	// versions created at the same time keep their order
+	addTestVersion(t, repo, store, model.StoreVersion{OwnerName: "Doe, John", CreatedAt: start.Add(3 * time.Hour)})
+
+	if err := repo.DeleteStoreVersion(ctx, strconv.Itoa(deleted.VersionID), "user1"); err != nil {
+		t.Fatalf("delete version 3: %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		filter model.StoreVersionFilter
+		want   []int
+	}{
+		{"oldest first", model.StoreVersionFilter{}, []int{1, 2, 4, 5}},
+		{"newest first", model.StoreVersionFilter{Descending: true}, []int{5, 4, 2, 1}},
+		{"by creator", model.StoreVersionFilter{CreatorLogin: "user2"}, []int{2, 4}},
+		{"created in a range", model.StoreVersionFilter{CreatedFrom: start.Add(time.Hour), CreatedTo: start.Add(3 * time.Hour)}, []int{2, 4, 5}},
+		{"created before", model.StoreVersionFilter{CreatedTo: start.Add(time.Hour), Descending: true}, []int{2, 1}},
+	}
+
+	for _, tt := range tests {
+		// pages of two are followed the way the service builds its cursors
+		filter := tt.filter
+		filter.Limit = 2
+
+		var got []int
+		for {
+			versions, err := repo.GetStoreVersionHistoryPage(ctx, storeID, filter)
+			if err != nil {
+				t.Fatalf("%s: %v", tt.name, err)
+			}
+			for _, version := range versions {
+				got = append(got, version.VersionNumber)
+			}
+			if len(versions) < filter.Limit {
+				break
+			}
+			filter.AfterNumber = versions[len(versions)-1].VersionNumber
+		}
+
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: got %v, want %v", tt.name, got, tt.want)
+		}
+	}
+
+	history, err := repo.GetStoreVersionHistory(ctx, storeID)
+	if err != nil {
+		t.Fatalf("full history: %v", err)
+	}
+	var numbers []int
+	for _, version := range history {
+		numbers = append(numbers, version.VersionNumber)
+	}
+	if !reflect.DeepEqual(numbers, []int{5, 4, 2, 1}) {
+		t.Errorf("full history %v, want [5 4 2 1]", numbers)
+	}
+
+	at, err := repo.GetStoreVersionAt(ctx, storeID, start.Add(150*time.Minute))
+	if err != nil || at.VersionNumber != 2 {
+		t.Errorf("version at 14:30 is %+v with error %v, want version 2 as 3 is deleted", at, err)
+	}
+}
@@ -0,0 +1,30 @@
+package requestid
+
+import (
+	"context"
+	"go.uber.org/zap"
+)
+
+// Header is the AMQP header carrying the request id set by the gateway
+const Header = "x-request-id"
+
+type contextKey struct{}
+
+func NewContext(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, contextKey{}, requestID)
+}
+
+func FromContext(ctx context.Context) string {
+	requestID, _ := ctx.Value(contextKey{}).(string)
+	return requestID
+}
+
+// Logger returns the logger annotated with the request id stored in ctx
+func Logger(ctx context.Context, logger *zap.Logger) *zap.Logger {
+	requestID := FromContext(ctx)
+	if requestID == "" {
+		return logger
+	}
+
+	return logger.With(zap.String("requestId", requestID))
+}
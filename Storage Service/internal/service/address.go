@@ -0,0 +1,39 @@
+package service
+
+import (
+	"StorageService/internal/model"
+	"strings"
+)
+
+// storeAddress returns the address string and its parts. A structured address wins over the string
+// and is formatted as "city, street, house", the string is split into parts the same way.
+// Without either both are empty
+func storeAddress(address string, structured *model.StructuredAddress) (string, model.StructuredAddress) {
+	if structured != nil {
+		return formatAddress(*structured), *structured
+	}
+
+	if strings.TrimSpace(address) == "" {
+		return "", model.StructuredAddress{}
+	}
+
+	return address, parseAddress(address)
+}
+
+// parseAddress splits a "city, street, house" address, anything after the second comma is the house
+func parseAddress(address string) model.StructuredAddress {
+	parts := strings.SplitN(address, ",", 3)
+	for len(parts) < 3 {
+		parts = append(parts, "")
+	}
+
+	return model.StructuredAddress{
+		City:   strings.TrimSpace(parts[0]),
+		Street: strings.TrimSpace(parts[1]),
+		House:  strings.TrimSpace(parts[2]),
+	}
+}
+
+func formatAddress(address model.StructuredAddress) string {
+	return address.City + ", " + address.Street + ", " + address.House
+}
@@ -0,0 +1,51 @@
+package service
+
+import (
+	"StorageService/internal/model"
+	"StorageService/internal/requestid"
+	"context"
+	"go.uber.org/zap"
+)
+
+// CityPage is a page of cities. NextCursor is empty on the last page
+type CityPage struct {
+	Cities     []*model.City
+	NextCursor string
+}
+
+// cityCursor is the key of the last city of a page
+type cityCursor struct {
+	Key string `json:"k"`
+}
+
+// ListCities returns a page of the cities with live stores in alphabetical order, each with its number of stores.
+// Cursor is the NextCursor of the previous page
+func (s *StoreService) ListCities(ctx context.Context, limit int, cursor string) (*CityPage, error) {
+	var after string
+	if cursor != "" {
+		var decoded cityCursor
+		if err := decodeCursor(cursor, &decoded); err != nil || decoded.Key == "" {
+			return nil, ErrInvalidCursor
+		}
+		after = decoded.Key
+	}
+
+	// one more city tells whether there is a next page
+	cities, err := s.repository.ListCities(ctx, limit+1, after)
+
+	if err != nil {
+		requestid.Logger(ctx, s.logger).With(
+			zap.String("place", "service"),
+			zap.Error(err),
+		).Error("Failed to list cities")
+		return nil, err
+	}
+
+	page := &CityPage{Cities: cities}
+	if len(cities) > limit {
+		page.Cities = cities[:limit]
+		page.NextCursor = encodeCursor(cityCursor{Key: page.Cities[limit-1].Key})
+	}
+
+	return page, nil
+}
@@ -0,0 +1,40 @@
+package service
+
+import (
+	"StorageService/internal/model"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+)
+
+// idempotentRequest returns nil for requests without a key. The hash covers the store and the payload,
+// so a key reused for another store or with other fields is told apart from a retry
+func idempotentRequest(key, storeID string, data interface{}) (*model.IdempotentRequest, error) {
+	if key == "" {
+		return nil, nil
+	}
+
+	body, err := json.Marshal(struct {
+		StoreID string      `json:"storeId"`
+		Data    interface{} `json:"data"`
+	}{storeID, data})
+	if err != nil {
+		return nil, err
+	}
+
+	hash := sha256.Sum256(body)
+	return &model.IdempotentRequest{Key: key, RequestHash: hex.EncodeToString(hash[:])}, nil
+}
+
+// versionFields are the fields of the version hashed by idempotentRequest. Preconditions are left out:
+// a client that lost the reply may get the store, which already has the version, and retry with its new ETag
+func versionFields(data StoreVersion) interface{} {
+	return struct {
+		Name              string                   `json:"name"`
+		Address           string                   `json:"address"`
+		OwnerName         string                   `json:"ownerName"`
+		OpeningTime       string                   `json:"openingTime"`
+		ClosingTime       string                   `json:"closingTime"`
+		StructuredAddress *model.StructuredAddress `json:"structuredAddress"`
+	}{data.Name, data.Address, data.OwnerName, data.OpeningTime, data.ClosingTime, data.StructuredAddress}
+}
@@ -0,0 +1,30 @@
+package service
+
+import "testing"
+
+func TestIdempotentRequest(t *testing.T) {
+	if request, _ := idempotentRequest("", "1", versionFields(StoreVersion{OwnerName: "a"})); request != nil {
+		t.Fatalf("got %+v without a key, want nil", request)
+	}
+
+	retry, _ := idempotentRequest("key", "1", versionFields(StoreVersion{OwnerName: "a"}))
+	first, _ := idempotentRequest("key", "1", versionFields(StoreVersion{OwnerName: "a"}))
+	if retry.RequestHash != first.RequestHash {
+		t.Error("a retry of the same request has another hash")
+	}
+
+	otherStore, _ := idempotentRequest("key", "2", versionFields(StoreVersion{OwnerName: "a"}))
+	if otherStore.RequestHash == first.RequestHash {
+		t.Error("a request for another store has the same hash")
+	}
+
+	otherData, _ := idempotentRequest("key", "1", versionFields(StoreVersion{OwnerName: "b"}))
+	if otherData.RequestHash == first.RequestHash {
+		t.Error("a request with other data has the same hash")
+	}
+
+	precondition, _ := idempotentRequest("key", "1", versionFields(StoreVersion{OwnerName: "a", ExpectedRevisions: []int64{3}}))
+	if precondition.RequestHash != first.RequestHash {
+		t.Error("a retry with another If-Match has another hash")
+	}
+}
@@ -0,0 +1,53 @@
+package service
+
+import "StorageService/internal/model"
+
+// Fields compared by StoreDiff
+const (
+	FieldName        = "name"
+	FieldAddress     = "address"
+	FieldPostalCode  = "postal_code"
+	FieldUnit        = "unit"
+	FieldOwnerName   = "owner_name"
+	FieldOpeningTime = "opening_time"
+	FieldClosingTime = "closing_time"
+)
+
+// StoreDiff compares two versions of a store field by field
+type StoreDiff struct {
+	From    *model.StoreVersion
+	To      *model.StoreVersion
+	Changes []FieldChange
+}
+
+type FieldChange struct {
+	Field   string
+	Before  string
+	After   string
+	Changed bool
+}
+
+// diffStoreVersions compares the versions. Compact diffs keep only the fields that changed
+func diffStoreVersions(from, to *model.StoreVersion, compact bool) *StoreDiff {
+	diff := &StoreDiff{From: from, To: to, Changes: []FieldChange{}}
+
+	fields := []FieldChange{
+		{Field: FieldName, Before: from.Name, After: to.Name},
+		{Field: FieldAddress, Before: from.Address, After: to.Address},
+		{Field: FieldPostalCode, Before: from.PostalCode, After: to.PostalCode},
+		{Field: FieldUnit, Before: from.Unit, After: to.Unit},
+		{Field: FieldOwnerName, Before: from.OwnerName, After: to.OwnerName},
+		{Field: FieldOpeningTime, Before: from.OpeningTime, After: to.OpeningTime},
+		{Field: FieldClosingTime, Before: from.ClosingTime, After: to.ClosingTime},
+	}
+
+	for _, field := range fields {
+		field.Changed = field.Before != field.After
+		if compact && !field.Changed {
+			continue
+		}
+		diff.Changes = append(diff.Changes, field)
+	}
+
+	return diff
+}
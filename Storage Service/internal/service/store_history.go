@@ -0,0 +1,92 @@
+package service
+
+import (
+	"StorageService/internal/model"
+	"StorageService/internal/requestid"
+	"context"
+	"go.uber.org/zap"
+	"time"
+)
+
+// HistoryQuery selects a page of the versions of a store, zero fields don't filter. At cuts the history
+// to the versions created up to that time, Cursor is the NextCursor of the previous page
+type HistoryQuery struct {
+	At           time.Time
+	CreatorLogin string
+	CreatedFrom  time.Time
+	CreatedTo    time.Time
+
+	Descending bool
+	Limit      int
+	Cursor     string
+}
+
+// StoreVersionPage is a page of the versions of a store. NextCursor is empty on the last page
+type StoreVersionPage struct {
+	Versions   []*model.StoreVersion
+	NextCursor string
+}
+
+// historyCursor is the number of the last version of a page. The order is kept
+// to reject cursors used with another order
+type historyCursor struct {
+	Descending    bool `json:"d,omitempty"`
+	VersionNumber int  `json:"n"`
+}
+
+// GetStoreVersionHistoryPage returns a page of the versions of the store ordered by number.
+// With query.At a store created later is not found
+func (s *StoreService) GetStoreVersionHistoryPage(ctx context.Context, storeID string, query HistoryQuery) (*StoreVersionPage, error) {
+	store, err := s.GetStoreByID(ctx, storeID)
+	if err != nil {
+		return nil, err
+	}
+
+	filter := model.StoreVersionFilter{
+		CreatorLogin: query.CreatorLogin,
+		CreatedFrom:  query.CreatedFrom,
+		CreatedTo:    query.CreatedTo,
+		Descending:   query.Descending,
+		// one more version tells whether there is a next page
+		Limit: query.Limit + 1,
+	}
+
+	if !query.At.IsZero() {
+		if store.CreatedAt.After(query.At) {
+			return nil, ErrStoreNotFound
+		}
+		if filter.CreatedTo.IsZero() || query.At.Before(filter.CreatedTo) {
+			filter.CreatedTo = query.At
+		}
+	}
+
+	if query.Cursor != "" {
+		var cursor historyCursor
+		err = decodeCursor(query.Cursor, &cursor)
+		if err != nil || cursor.VersionNumber <= 0 || cursor.Descending != query.Descending {
+			return nil, ErrInvalidCursor
+		}
+		filter.AfterNumber = cursor.VersionNumber
+	}
+
+	storeVersions, err := s.repository.GetStoreVersionHistoryPage(ctx, storeID, filter)
+
+	if err != nil {
+		requestid.Logger(ctx, s.logger).With(
+			zap.String("place", "service"),
+			zap.Error(err),
+		).Error("Failed to get store history page")
+		return nil, err
+	}
+
+	page := &StoreVersionPage{Versions: storeVersions}
+	if len(storeVersions) > query.Limit {
+		page.Versions = storeVersions[:query.Limit]
+		page.NextCursor = encodeCursor(historyCursor{
+			Descending:    query.Descending,
+			VersionNumber: page.Versions[query.Limit-1].VersionNumber,
+		})
+	}
+
+	return page, nil
+}
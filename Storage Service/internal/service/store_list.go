@@ -0,0 +1,141 @@
+package service
+
+import (
+	"StorageService/internal/model"
+	"StorageService/internal/requestid"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"go.uber.org/zap"
+	"time"
+)
+
+// StoreQuery selects a page of stores, zero fields don't filter. SortBy is model.StoreSortName
+// or model.StoreSortCreatedAt, Cursor is the NextCursor of the previous page
+type StoreQuery struct {
+	CreatorLogin string
+	NamePrefix   string
+	City         string
+	CreatedFrom  time.Time
+	CreatedTo    time.Time
+
+	SortBy     string
+	Descending bool
+	Limit      int
+	Cursor     string
+}
+
+// StorePage is a page of stores. NextCursor is empty on the last page
+type StorePage struct {
+	Stores     []*model.Store
+	NextCursor string
+}
+
+// storeCursor is the position after the last store of a page. The sort order is kept
+// to reject cursors used with another order
+type storeCursor struct {
+	SortBy     string `json:"s"`
+	Descending bool   `json:"d,omitempty"`
+	Value      string `json:"v"`
+	StoreID    int    `json:"id"`
+}
+
+// ListStores returns a page of live stores with the name, address, owner and hours of their last version
+func (s *StoreService) ListStores(ctx context.Context, query StoreQuery) (*StorePage, error) {
+	filter := model.StoreFilter{
+		CreatorLogin: query.CreatorLogin,
+		NamePrefix:   query.NamePrefix,
+		City:         query.City,
+		CreatedFrom:  query.CreatedFrom,
+		CreatedTo:    query.CreatedTo,
+		SortBy:       query.SortBy,
+		Descending:   query.Descending,
+		// one more store tells whether there is a next page
+		Limit: query.Limit + 1,
+	}
+
+	if query.Cursor != "" {
+		cursor, err := decodeStoreCursor(query.Cursor)
+		if err != nil || cursor.SortBy != query.SortBy || cursor.Descending != query.Descending {
+			return nil, ErrInvalidCursor
+		}
+		filter.AfterValue = cursor.Value
+		filter.AfterID = cursor.StoreID
+	}
+
+	stores, err := s.repository.ListStores(ctx, filter)
+
+	if err != nil {
+		requestid.Logger(ctx, s.logger).With(
+			zap.String("place", "service"),
+			zap.Error(err),
+		).Error("Failed to list stores")
+		return nil, err
+	}
+
+	page := &StorePage{Stores: stores}
+	if len(stores) > query.Limit {
+		page.Stores = stores[:query.Limit]
+		page.NextCursor = encodeStoreCursor(query, page.Stores[query.Limit-1])
+	}
+
+	return page, nil
+}
+
+// SearchStores returns up to limit stores whose name, address or owner match the text, best match first
+func (s *StoreService) SearchStores(ctx context.Context, text string, limit int) ([]*model.StoreSearchResult, error) {
+	results, err := s.repository.SearchStores(ctx, text, limit)
+
+	if err != nil {
+		requestid.Logger(ctx, s.logger).With(
+			zap.String("place", "service"),
+			zap.Error(err),
+		).Error("Failed to search stores")
+		return nil, err
+	}
+
+	return results, nil
+}
+
+func encodeStoreCursor(query StoreQuery, last *model.Store) string {
+	cursor := storeCursor{
+		SortBy:     query.SortBy,
+		Descending: query.Descending,
+		Value:      last.CreatedAt.Format(time.RFC3339Nano),
+		StoreID:    last.StoreID,
+	}
+	if query.SortBy == model.StoreSortName {
+		cursor.Value = last.Name
+	}
+
+	return encodeCursor(cursor)
+}
+
+func decodeStoreCursor(value string) (*storeCursor, error) {
+	var cursor storeCursor
+	if err := decodeCursor(value, &cursor); err != nil {
+		return nil, err
+	}
+
+	if cursor.StoreID <= 0 {
+		return nil, ErrInvalidCursor
+	}
+
+	return &cursor, nil
+}
+
+// encodeCursor turns the position after the last item of a page into an opaque string
+func encodeCursor(cursor interface{}) string {
+	// cursors are structs of strings, ints and bools, marshalling them can't fail
+	body, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(body)
+}
+
+func decodeCursor(value string, cursor interface{}) error {
+	body, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(body, cursor)
+}
@@ -2,42 +2,82 @@ package service
 
 import (
 	"StorageService/internal/model"
+	"StorageService/internal/requestid"
+	"context"
+	"database/sql"
 	"errors"
 	"go.uber.org/zap"
+	"sort"
 	"time"
 )
 
 type Repository interface {
-	CreateStore(store model.Store) error
-	CreateStoreVersion(storeVersion model.StoreVersion) error
-	DeleteStore(storeId string) error
-	DeleteStoreVersion(versionId string) error
-	GetStoreByID(storeId string) (*model.Store, error)
-	GetStoreVersionHistory(storeId string) ([]*model.StoreVersion, error)
-	GetStoreVersionByID(versionId string) (*model.StoreVersion, error)
-	GetStoreVersionForStore(storeId, versionId string) (*model.StoreVersion, error)
-	CheckStoreCreator(storeId, login string) error
+	CreateStore(ctx context.Context, store model.Store, idempotent *model.IdempotentRequest) (*model.Store, error)
+	CreateStoreVersion(ctx context.Context, storeVersion model.StoreVersion, idempotent *model.IdempotentRequest, precondition model.StorePrecondition) (*model.StoreVersion, error)
+	RestoreStoreVersion(ctx context.Context, storeVersion model.StoreVersion, restoredVersionId string) (*model.StoreVersion, error)
+	DeleteStore(ctx context.Context, storeId, login string) error
+	DeleteStoreVersion(ctx context.Context, versionId, login string) error
+	UndeleteStore(ctx context.Context, storeId string) error
+	UndeleteStoreVersion(ctx context.Context, versionId string) error
+	GetDeletedStoreByID(ctx context.Context, storeId string) (*model.Store, error)
+	GetDeletedStoreVersionForStore(ctx context.Context, storeId, versionId string) (*model.StoreVersion, error)
+	GetStoreByID(ctx context.Context, storeId string) (*model.Store, error)
+	GetStoreVersionHistory(ctx context.Context, storeId string) ([]*model.StoreVersion, error)
+	GetStoreVersionAt(ctx context.Context, storeId string, at time.Time) (*model.StoreVersion, error)
+	GetStoreVersionHistoryPage(ctx context.Context, storeId string, filter model.StoreVersionFilter) ([]*model.StoreVersion, error)
+	GetStoreVersionByID(ctx context.Context, versionId string) (*model.StoreVersion, error)
+	GetStoreVersionForStore(ctx context.Context, storeId, versionId string) (*model.StoreVersion, error)
+	ListStores(ctx context.Context, filter model.StoreFilter) ([]*model.Store, error)
+	ListCities(ctx context.Context, limit int, afterKey string) ([]*model.City, error)
+	SearchStores(ctx context.Context, text string, limit int) ([]*model.StoreSearchResult, error)
+	CheckStoreCreator(ctx context.Context, storeId, login string) error
+}
+
+// now returns the creation time of new stores and versions in the microseconds timestamptz keeps,
+// so the stored time is the same as the returned one
+func now() time.Time {
+	return time.Now().Round(time.Microsecond)
 }
 
 var (
 	ErrVersionNotFound  = errors.New("store version not found")
 	ErrStoreNotFound    = errors.New("store not found")
 	ErrPermissionDenied = errors.New("user is not a store creator")
+	// ErrIdempotencyKeyConflict means the key was already used for another action or request
+	ErrIdempotencyKeyConflict = model.ErrIdempotencyKeyConflict
+	// ErrOnlyVersion means the last remaining version of a store can't be deleted
+	ErrOnlyVersion = model.ErrOnlyVersion
+	// ErrPreconditionFailed means the store revision is not one the client expected
+	ErrPreconditionFailed = model.ErrVersionMismatch
+	// ErrInvalidCursor means the cursor is malformed or was issued for another sort order
+	ErrInvalidCursor = errors.New("invalid cursor")
 )
 
+// Store is a new store, StructuredAddress wins over Address when it is set
 type Store struct {
 	Name        string
 	Address     string
 	OwnerName   string
 	OpeningTime string
 	ClosingTime string
+
+	StructuredAddress *model.StructuredAddress
 }
 
+// StoreVersion is a new version of a store, empty fields keep the value of the previous version.
+// The address is given as in Store. When ExpectedRevisions are given, one of them must be
+// the store revision, otherwise the version is not created. IfMatchAny only requires the store to exist
 type StoreVersion struct {
+	Name        string
+	Address     string
 	OwnerName   string
 	OpeningTime string
 	ClosingTime string
 	CreatedAt   string
+
+	StructuredAddress *model.StructuredAddress
+	ExpectedRevisions []int64
+	IfMatchAny        bool
 }
 
 type StoreService struct {
@@ -52,92 +92,157 @@ func NewStoreService(logger *zap.Logger, repository Repository) *StoreService {
 	}
 }
 
-func (s *StoreService) CreateStore(data Store, login string) error {
+// CreateStore creates the store. Repeated calls with the same non-empty idempotencyKey
+// return the store created by the first one, as long as they send the same data
+func (s *StoreService) CreateStore(ctx context.Context, data Store, login, idempotencyKey string) (*model.Store, error) {
 	storeModel := model.Store{
 		Name:         data.Name,
-		Address:      data.Address,
 		CreatorLogin: login,
 		OwnerName:    data.OwnerName,
 		OpeningTime:  data.OpeningTime,
 		ClosingTime:  data.ClosingTime,
-		CreatedAt:    time.Now().Format("2006-01-02 15:04:05"),
+		CreatedAt:    now(),
+	}
+	storeModel.Address, storeModel.StructuredAddress = storeAddress(data.Address, data.StructuredAddress)
+
+	idempotent, err := idempotentRequest(idempotencyKey, "", data)
+	if err != nil {
+		return nil, err
 	}
 
-	err := s.repository.CreateStore(storeModel)
+	store, err := s.repository.CreateStore(ctx, storeModel, idempotent)
 
 	if err != nil {
-		s.logger.With(
+		requestid.Logger(ctx, s.logger).With(
 			zap.String("place", "service"),
 			zap.Error(err),
 		).Error("Failed to create store")
-		return err
+		return nil, err
 	}
-	return nil
+	return store, nil
 }
 
-func (s *StoreService) CreateStoreVersion(data StoreVersion, storeID, login string) error {
-	_, err := s.repository.GetStoreByID(storeID)
+// CreateStoreVersion adds a version to the store. Repeated calls with the same non-empty
+// idempotencyKey return the version created by the first one, as long as they send the same store and data.
+// With a precondition a missing or deleted store fails with ErrPreconditionFailed instead of ErrStoreNotFound
+func (s *StoreService) CreateStoreVersion(ctx context.Context, data StoreVersion, storeID, login, idempotencyKey string) (*model.StoreVersion, error) {
+	precondition := model.StorePrecondition{Revisions: data.ExpectedRevisions, Exists: data.IfMatchAny}
 
-	s.logger.Info("Here")
+	_, err := s.repository.GetStoreByID(ctx, storeID)
 
 	if err != nil {
-		s.logger.With(
+		requestid.Logger(ctx, s.logger).With(
 			zap.String("place", "service"),
 			zap.Error(err),
 		).Error("Failed to get store")
-		return ErrStoreNotFound
+		if precondition.IsSet() {
+			return nil, ErrPreconditionFailed
+		}
+		return nil, ErrStoreNotFound
 	}
 
 	storeVersionModel := model.StoreVersion{
 		StoreID:       storeID,
 		VersionNumber: 0,
 		CreatorLogin:  login,
+		Name:          data.Name,
 		OwnerName:     data.OwnerName,
 		OpeningTime:   data.OpeningTime,
 		ClosingTime:   data.ClosingTime,
-		CreatedAt:     time.Now().Format("2006-01-02 15:04:05"),
+		CreatedAt:     now(),
 		IsLast:        true,
 	}
+	storeVersionModel.Address, storeVersionModel.StructuredAddress = storeAddress(data.Address, data.StructuredAddress)
+
+	idempotent, err := idempotentRequest(idempotencyKey, storeID, versionFields(data))
+	if err != nil {
+		return nil, err
+	}
 
-	err = s.repository.CreateStoreVersion(storeVersionModel)
+	storeVersion, err := s.repository.CreateStoreVersion(ctx, storeVersionModel, idempotent, precondition)
 
 	if err != nil {
-		s.logger.With(
+		requestid.Logger(ctx, s.logger).With(
 			zap.String("place", "service"),
 			zap.Error(err),
 		).Error("Failed to create store version")
-		return err
+		return nil, err
 	}
 
-	return nil
+	return storeVersion, nil
 
 }
 
-func (s *StoreService) DeleteStore(storeID, login string) error {
-	_, err := s.repository.GetStoreByID(storeID)
+// RestoreStoreVersion adds a version to the store with the fields of one of its older versions.
+// History is kept, the new version records which version it was restored from and who restored it
+func (s *StoreService) RestoreStoreVersion(ctx context.Context, storeID, versionID, login string) (*model.StoreVersion, error) {
+	_, err := s.repository.GetStoreVersionForStore(ctx, storeID, versionID)
 
 	if err != nil {
-		s.logger.With(
+		requestid.Logger(ctx, s.logger).With(
+			zap.String("place", "service"),
+			zap.Error(err),
+		).Error("Failed to get store version")
+		return nil, ErrVersionNotFound
+	}
+
+	storeVersionModel := model.StoreVersion{
+		StoreID:      storeID,
+		CreatorLogin: login,
+		CreatedAt:    now(),
+		IsLast:       true,
+	}
+
+	storeVersion, err := s.repository.RestoreStoreVersion(ctx, storeVersionModel, versionID)
+
+	if errors.Is(err, sql.ErrNoRows) {
+		// deleted after the check above
+		return nil, ErrVersionNotFound
+	}
+
+	if err != nil {
+		requestid.Logger(ctx, s.logger).With(
+			zap.String("place", "service"),
+			zap.Error(err),
+		).Error("Failed to restore store version")
+		return nil, err
+	}
+
+	return storeVersion, nil
+}
+
+// DeleteStore marks the store and its versions as deleted, only the creator of the store can do it.
+// They are hidden from reads and can be undeleted until they are purged
+func (s *StoreService) DeleteStore(ctx context.Context, storeID, login string) error {
+	_, err := s.repository.GetStoreByID(ctx, storeID)
+
+	if err != nil {
+		requestid.Logger(ctx, s.logger).With(
 			zap.String("place", "service"),
 			zap.Error(err),
 		).Error("Failed to get store")
 		return ErrStoreNotFound
 	}
 
-	err = s.repository.CheckStoreCreator(storeID, login)
+	err = s.repository.CheckStoreCreator(ctx, storeID, login)
 
 	if err != nil {
-		s.logger.With(
+		requestid.Logger(ctx, s.logger).With(
 			zap.String("place", "service"),
 			zap.Error(err),
 		).Error("Only creator can delete the store")
 		return ErrPermissionDenied
 	}
 
-	err = s.repository.DeleteStore(storeID)
+	err = s.repository.DeleteStore(ctx, storeID, login)
+
+	if errors.Is(err, sql.ErrNoRows) {
+		// deleted by someone else after the check above
+		return ErrStoreNotFound
+	}
 
 	if err != nil {
-		s.logger.With(
+		requestid.Logger(ctx, s.logger).With(
 			zap.String("place", "service"),
 			zap.Error(err),
 		).Error("Failed to delete store")
@@ -146,32 +251,37 @@ func (s *StoreService) DeleteStore(storeID, login string) error {
 	return nil
 }
 
-func (s *StoreService) DeleteStoreVersion(storeID, versionID, login string) error {
+// DeleteStoreVersion marks the version as deleted, only the creator of the store can do it
+func (s *StoreService) DeleteStoreVersion(ctx context.Context, storeID, versionID, login string) error {
 
-	_, err := s.repository.GetStoreVersionForStore(storeID, versionID)
+	_, err := s.repository.GetStoreVersionForStore(ctx, storeID, versionID)
 
 	if err != nil {
-		s.logger.With(
+		requestid.Logger(ctx, s.logger).With(
 			zap.String("place", "service"),
 			zap.Error(err),
 		).Error("Failed to get store")
 		return ErrVersionNotFound
 	}
 
-	err = s.repository.CheckStoreCreator(storeID, login)
+	err = s.repository.CheckStoreCreator(ctx, storeID, login)
 
 	if err != nil {
-		s.logger.With(
+		requestid.Logger(ctx, s.logger).With(
 			zap.String("place", "service"),
 			zap.Error(err),
 		).Error("Only creator of the store can delete the store version")
 		return ErrPermissionDenied
 	}
 
-	err = s.repository.DeleteStoreVersion(versionID)
+	err = s.repository.DeleteStoreVersion(ctx, versionID, login)
+
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrVersionNotFound
+	}
 
 	if err != nil {
-		s.logger.With(
+		requestid.Logger(ctx, s.logger).With(
 			zap.String("place", "service"),
 			zap.Error(err),
 		).Error("Failed to delete store version")
@@ -180,11 +290,99 @@ func (s *StoreService) DeleteStoreVersion(storeID, versionID, login string) erro
 	return nil
 }
 
-func (s *StoreService) GetStoreByID(storeID string) (*model.Store, error) {
-	store, err := s.repository.GetStoreByID(storeID)
+// UndeleteStore brings back a deleted store with the versions deleted with it, only the creator can do it
+func (s *StoreService) UndeleteStore(ctx context.Context, storeID, login string) error {
+	_, err := s.repository.GetDeletedStoreByID(ctx, storeID)
+
+	if err != nil {
+		requestid.Logger(ctx, s.logger).With(
+			zap.String("place", "service"),
+			zap.Error(err),
+		).Error("Failed to get deleted store")
+		return ErrStoreNotFound
+	}
+
+	err = s.repository.CheckStoreCreator(ctx, storeID, login)
+
+	if err != nil {
+		requestid.Logger(ctx, s.logger).With(
+			zap.String("place", "service"),
+			zap.Error(err),
+		).Error("Only creator can undelete the store")
+		return ErrPermissionDenied
+	}
+
+	err = s.repository.UndeleteStore(ctx, storeID)
+
+	if errors.Is(err, sql.ErrNoRows) {
+		// undeleted by someone else after the check above
+		return ErrStoreNotFound
+	}
+
+	if err != nil {
+		requestid.Logger(ctx, s.logger).With(
+			zap.String("place", "service"),
+			zap.Error(err),
+		).Error("Failed to undelete store")
+		return err
+	}
+	return nil
+}
+
+// UndeleteStoreVersion brings back a deleted version of a store that isn't deleted itself,
+// only the creator of the store can do it
+func (s *StoreService) UndeleteStoreVersion(ctx context.Context, storeID, versionID, login string) error {
+	_, err := s.repository.GetStoreByID(ctx, storeID)
 
 	if err != nil {
-		s.logger.With(
+		requestid.Logger(ctx, s.logger).With(
+			zap.String("place", "service"),
+			zap.Error(err),
+		).Error("Failed to get store")
+		return ErrStoreNotFound
+	}
+
+	_, err = s.repository.GetDeletedStoreVersionForStore(ctx, storeID, versionID)
+
+	if err != nil {
+		requestid.Logger(ctx, s.logger).With(
+			zap.String("place", "service"),
+			zap.Error(err),
+		).Error("Failed to get deleted store version")
+		return ErrVersionNotFound
+	}
+
+	err = s.repository.CheckStoreCreator(ctx, storeID, login)
+
+	if err != nil {
+		requestid.Logger(ctx, s.logger).With(
+			zap.String("place", "service"),
+			zap.Error(err),
+		).Error("Only creator of the store can undelete the store version")
+		return ErrPermissionDenied
+	}
+
+	err = s.repository.UndeleteStoreVersion(ctx, versionID)
+
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrVersionNotFound
+	}
+
+	if err != nil {
+		requestid.Logger(ctx, s.logger).With(
+			zap.String("place", "service"),
+			zap.Error(err),
+		).Error("Failed to undelete store version")
+		return err
+	}
+	return nil
+}
+
+func (s *StoreService) GetStoreByID(ctx context.Context, storeID string) (*model.Store, error) {
+	store, err := s.repository.GetStoreByID(ctx, storeID)
+
+	if err != nil {
+		requestid.Logger(ctx, s.logger).With(
 			zap.String("place", "service"),
 			zap.Error(err),
 		).Error("Failed to get store")
@@ -194,11 +392,11 @@ func (s *StoreService) GetStoreByID(storeID string) (*model.Store, error) {
 	return store, nil
 }
 
-func (s *StoreService) GetStoreVersionHistory(storeID string) ([]*model.StoreVersion, error) {
-	storeHistory, err := s.repository.GetStoreVersionHistory(storeID)
+func (s *StoreService) GetStoreVersionHistory(ctx context.Context, storeID string) ([]*model.StoreVersion, error) {
+	storeHistory, err := s.repository.GetStoreVersionHistory(ctx, storeID)
 
 	if err != nil {
-		s.logger.With(
+		requestid.Logger(ctx, s.logger).With(
 			zap.String("place", "service"),
 			zap.Error(err),
 		).Error("Failed to get storeHistory version")
@@ -213,21 +411,53 @@ func (s *StoreService) GetStoreVersionHistory(storeID string) ([]*model.StoreVer
 
 }
 
-func (s *StoreService) GetStoreVersionByID(storeID, versionID string) (*model.StoreVersion, error) {
-	_, err := s.repository.GetStoreVersionForStore(storeID, versionID)
+// GetStoreAt returns the store with the name, address, owner and hours of the version that was current at the given time.
+// Stores created later are not found
+func (s *StoreService) GetStoreAt(ctx context.Context, storeID string, at time.Time) (*model.Store, error) {
+	store, err := s.GetStoreByID(ctx, storeID)
+	if err != nil {
+		return nil, err
+	}
+
+	storeVersion, err := s.repository.GetStoreVersionAt(ctx, storeID, at)
 
 	if err != nil {
-		s.logger.With(
+		requestid.Logger(ctx, s.logger).With(
+			zap.String("place", "service"),
+			zap.Time("at", at),
+			zap.Error(err),
+		).Error("Failed to get store version at the given time")
+		return nil, ErrStoreNotFound
+	}
+
+	store.Name = storeVersion.Name
+	store.Address = storeVersion.Address
+	store.StructuredAddress = storeVersion.StructuredAddress
+	store.OwnerName = storeVersion.OwnerName
+	store.OpeningTime = storeVersion.OpeningTime
+	store.ClosingTime = storeVersion.ClosingTime
+	store.LatestVersionNumber = storeVersion.VersionNumber
+	// the revision is of the current store, not of the store at that time
+	store.Revision = 0
+
+	return store, nil
+}
+
+func (s *StoreService) GetStoreVersionByID(ctx context.Context, storeID, versionID string) (*model.StoreVersion, error) {
+	_, err := s.repository.GetStoreVersionForStore(ctx, storeID, versionID)
+
+	if err != nil {
+		requestid.Logger(ctx, s.logger).With(
 			zap.String("place", "service"),
 			zap.Error(err),
 		).Error("Failed to get store")
 		return nil, ErrVersionNotFound
 	}
 
-	storeVersion, err := s.repository.GetStoreVersionByID(versionID)
+	storeVersion, err := s.repository.GetStoreVersionByID(ctx, versionID)
 
 	if err != nil {
-		s.logger.With(
+		requestid.Logger(ctx, s.logger).With(
 			zap.String("place", "service"),
 			zap.Error(err),
 		).Error("Failed to get storeVersion version")
@@ -236,3 +466,48 @@ func (s *StoreService) GetStoreVersionByID(storeID, versionID string) (*model.St
 
 	return storeVersion, nil
 }
+
+// GetStoreDiff compares two versions of the store
+func (s *StoreService) GetStoreDiff(ctx context.Context, storeID, fromVersionID, toVersionID string) (*StoreDiff, error) {
+	from, err := s.repository.GetStoreVersionForStore(ctx, storeID, fromVersionID)
+
+	if err != nil {
+		requestid.Logger(ctx, s.logger).With(
+			zap.String("place", "service"),
+			zap.Error(err),
+		).Error("Failed to get store version to diff from")
+		return nil, ErrVersionNotFound
+	}
+
+	to, err := s.repository.GetStoreVersionForStore(ctx, storeID, toVersionID)
+
+	if err != nil {
+		requestid.Logger(ctx, s.logger).With(
+			zap.String("place", "service"),
+			zap.Error(err),
+		).Error("Failed to get store version to diff to")
+		return nil, ErrVersionNotFound
+	}
+
+	return diffStoreVersions(from, to, false), nil
+}
+
+// GetStoreHistoryDiff compares every two consecutive versions of the store, oldest first.
+// Only changed fields are listed
+func (s *StoreService) GetStoreHistoryDiff(ctx context.Context, storeID string) ([]*StoreDiff, error) {
+	storeHistory, err := s.GetStoreVersionHistory(ctx, storeID)
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(storeHistory, func(i, j int) bool {
+		return storeHistory[i].VersionNumber < storeHistory[j].VersionNumber
+	})
+
+	diffs := make([]*StoreDiff, 0, len(storeHistory))
+	for i := 1; i < len(storeHistory); i++ {
+		diffs = append(diffs, diffStoreVersions(storeHistory[i-1], storeHistory[i], true))
+	}
+
+	return diffs, nil
+}